@@ -14,7 +14,10 @@ type printAwareHandler struct {
 }
 
 func (p *printAwareHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	// Delegate to underlying handler; LevelPrint is used via direct Handle calls.
+	// LevelPrint is not filtered by the log level, only by quiet mode.
+	if level == LevelPrint {
+		return !quiet.Load()
+	}
 	return p.h.Enabled(ctx, level)
 }
 
@@ -26,6 +29,14 @@ func (p *printAwareHandler) Handle(ctx context.Context, r slog.Record) error {
 	return p.h.Handle(ctx, r)
 }
 
+func (p *printAwareHandler) Flush() error {
+	return flushHandler(p.h)
+}
+
+func (p *printAwareHandler) Close() error {
+	return closeHandler(p.h)
+}
+
 func (p *printAwareHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &printAwareHandler{h: p.h.WithAttrs(attrs), w: p.w}
 }
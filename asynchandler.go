@@ -0,0 +1,242 @@
+package eslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DropPolicy defines what an AsyncHandler does if its queue is full.
+type DropPolicy int
+
+const (
+	// BlockPolicy blocks the logging goroutine until there is space in the queue.
+	BlockPolicy DropPolicy = iota
+	// DropOldestPolicy removes the oldest queued record to make space for the new one.
+	DropOldestPolicy
+	// DropNewestPolicy drops the new record.
+	DropNewestPolicy
+)
+
+// defaultQueueSize is used if AsyncOptions.QueueSize is not set.
+const defaultQueueSize = 1024
+
+// ErrHandlerClosed is returned by handlers which are already closed.
+var ErrHandlerClosed = errors.New("eslog: handler closed")
+
+// AsyncOptions configures an AsyncHandler.
+type AsyncOptions struct {
+	// QueueSize is the number of records which can be queued. Defaults to 1024.
+	QueueSize int
+	// Policy defines what happens if the queue is full. Defaults to BlockPolicy.
+	Policy DropPolicy
+	// DropReportInterval is the interval in which the number of dropped records is
+	// logged at LevelWarn. 0 disables the periodic report. Dropped records are reported on
+	// Close anyway.
+	DropReportInterval time.Duration
+}
+
+// AsyncHandler passes records to the wrapped handler in a background goroutine, so slow
+// writers don't add latency to the logging goroutine. Call Close (or Close of the Logger)
+// before the program exits to write all queued records. Fatal does this automatically.
+type AsyncHandler struct {
+	h     slog.Handler
+	state *asyncState
+}
+
+// asyncEntry is a queued record together with the handler which has to handle it. The
+// handler differs from the wrapped handler for handlers created by WithAttrs or WithGroup.
+type asyncEntry struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// asyncState is shared between an AsyncHandler and the handlers derived from it.
+type asyncState struct {
+	opts  AsyncOptions
+	queue chan asyncEntry
+	// report is the wrapped handler which receives the dropped records reports.
+	report slog.Handler
+
+	// closeMu is held for reading while sending to queue and for writing while closing it.
+	closeMu sync.RWMutex
+	closed  bool
+
+	// pendingMu guards pending which counts queued records which are not handled yet.
+	pendingMu sync.Mutex
+	pending   int
+	drained   *sync.Cond
+
+	dropped      atomic.Uint64
+	totalDropped atomic.Uint64
+
+	stopReport chan struct{}
+	done       sync.WaitGroup
+}
+
+// NewAsyncHandler wraps h in an AsyncHandler and starts its background goroutine.
+func NewAsyncHandler(h slog.Handler, opts AsyncOptions) *AsyncHandler {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	state := &asyncState{
+		opts:       opts,
+		queue:      make(chan asyncEntry, opts.QueueSize),
+		report:     h,
+		stopReport: make(chan struct{}),
+	}
+	state.drained = sync.NewCond(&state.pendingMu)
+
+	state.done.Add(1)
+	go state.work()
+	if opts.DropReportInterval > 0 {
+		state.done.Add(1)
+		go state.reportDropped()
+	}
+	return &AsyncHandler{h: h, state: state}
+}
+
+func (a *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return a.h.Enabled(ctx, level)
+}
+
+// Handle queues r. It only returns an error if the handler is closed.
+func (a *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	return a.state.enqueue(asyncEntry{ctx: context.WithoutCancel(ctx), h: a.h, r: r.Clone()})
+}
+
+func (a *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{h: a.h.WithAttrs(attrs), state: a.state}
+}
+
+func (a *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{h: a.h.WithGroup(name), state: a.state}
+}
+
+// Dropped returns the total number of records dropped because the queue was full.
+func (a *AsyncHandler) Dropped() uint64 {
+	return a.state.totalDropped.Load()
+}
+
+// Flush blocks until all records queued so far are handled and flushes the wrapped
+// handler.
+func (a *AsyncHandler) Flush() error {
+	a.state.waitDrained()
+	return flushHandler(a.h)
+}
+
+// Close stops accepting records, waits until all queued records are handled and closes
+// the wrapped handler. Closing a handler closes all handlers derived from it.
+func (a *AsyncHandler) Close() error {
+	s := a.state
+	s.closeMu.Lock()
+	if s.closed {
+		s.closeMu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	close(s.stopReport)
+	s.closeMu.Unlock()
+
+	s.done.Wait()
+	s.logDropped()
+	return closeHandler(a.h)
+}
+
+func (s *asyncState) enqueue(e asyncEntry) error {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.closed {
+		return ErrHandlerClosed
+	}
+	s.addPending(1)
+	switch s.opts.Policy {
+	case DropNewestPolicy:
+		select {
+		case s.queue <- e:
+		default:
+			s.drop()
+		}
+	case DropOldestPolicy:
+		for {
+			select {
+			case s.queue <- e:
+				return nil
+			default:
+			}
+			select {
+			case <-s.queue:
+				s.drop()
+			default:
+			}
+		}
+	default:
+		s.queue <- e
+	}
+	return nil
+}
+
+// drop counts a dropped record.
+func (s *asyncState) drop() {
+	s.dropped.Add(1)
+	s.totalDropped.Add(1)
+	s.addPending(-1)
+}
+
+func (s *asyncState) addPending(delta int) {
+	s.pendingMu.Lock()
+	s.pending += delta
+	if s.pending == 0 {
+		s.drained.Broadcast()
+	}
+	s.pendingMu.Unlock()
+}
+
+func (s *asyncState) waitDrained() {
+	s.pendingMu.Lock()
+	for s.pending > 0 {
+		s.drained.Wait()
+	}
+	s.pendingMu.Unlock()
+}
+
+// work handles queued records until the queue is closed.
+func (s *asyncState) work() {
+	defer s.done.Done()
+	for e := range s.queue {
+		_ = safeHandle(e.ctx, e.h, e.r)
+		s.addPending(-1)
+	}
+}
+
+// reportDropped periodically logs the number of dropped records.
+func (s *asyncState) reportDropped() {
+	defer s.done.Done()
+	ticker := time.NewTicker(s.opts.DropReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.logDropped()
+		case <-s.stopReport:
+			return
+		}
+	}
+}
+
+// logDropped logs the number of records dropped since the last report, if any.
+func (s *asyncState) logDropped() {
+	n := s.dropped.Swap(0)
+	if n == 0 {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "eslog: dropped log records", 0)
+	r.AddAttrs(slog.Uint64("dropped", n))
+	_ = safeHandle(context.Background(), s.report, r)
+}
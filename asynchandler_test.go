@@ -0,0 +1,169 @@
+package eslog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// recordingHandler records the messages of all handled records. If block is set each
+// Handle waits until a value is received from block.
+type recordingHandler struct {
+	mu       sync.Mutex
+	messages []string
+	attrs    []slog.Attr
+	block    chan struct{}
+	started  chan struct{}
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	if h.started != nil {
+		h.started <- struct{}{}
+	}
+	if h.block != nil {
+		<-h.block
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		h.attrs = append(h.attrs, a)
+		return true
+	})
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+func (h *recordingHandler) Messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string{}, h.messages...)
+}
+
+func handleMessage(t *testing.T, h slog.Handler, msg string) {
+	t.Helper()
+	err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0))
+	assert.NoError(t, err)
+}
+
+func TestAsyncHandlerFlush(t *testing.T) {
+	rec := &recordingHandler{}
+	h := NewAsyncHandler(rec, AsyncOptions{})
+	defer func() { _ = h.Close() }()
+
+	for _, msg := range []string{"one", "two", "three"} {
+		handleMessage(t, h, msg)
+	}
+	assert.NoError(t, h.Flush())
+
+	assert.Equal(t, []string{"one", "two", "three"}, rec.Messages())
+}
+
+func TestAsyncHandlerDropPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   DropPolicy
+		expected []string
+	}{
+		{"drop newest", DropNewestPolicy, []string{"one", "two", "eslog: dropped log records"}},
+		{"drop oldest", DropOldestPolicy, []string{"one", "three", "eslog: dropped log records"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingHandler{block: make(chan struct{}), started: make(chan struct{}, 4)}
+			h := NewAsyncHandler(rec, AsyncOptions{QueueSize: 1, Policy: tt.policy})
+
+			handleMessage(t, h, "one")
+			// Wait until the worker is blocked in handling "one", so the queue is empty.
+			<-rec.started
+			handleMessage(t, h, "two")
+			handleMessage(t, h, "three")
+			close(rec.block)
+			assert.NoError(t, h.Close())
+
+			assert.Equal(t, tt.expected, rec.Messages())
+			assert.Equal(t, uint64(1), h.Dropped())
+			assert.Equal(t, slog.Uint64("dropped", 1), rec.attrs[0])
+		})
+	}
+}
+
+func TestAsyncHandlerBlockPolicy(t *testing.T) {
+	rec := &recordingHandler{block: make(chan struct{}), started: make(chan struct{}, 4)}
+	h := NewAsyncHandler(rec, AsyncOptions{QueueSize: 1, Policy: BlockPolicy})
+
+	handleMessage(t, h, "one")
+	<-rec.started
+	handleMessage(t, h, "two")
+
+	done := make(chan struct{})
+	go func() {
+		handleMessage(t, h, "three")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Handle should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(rec.block)
+	<-done
+	assert.NoError(t, h.Close())
+	assert.Equal(t, []string{"one", "two", "three"}, rec.Messages())
+	assert.Equal(t, uint64(0), h.Dropped())
+}
+
+func TestAsyncHandlerDropReportInterval(t *testing.T) {
+	rec := &recordingHandler{block: make(chan struct{}), started: make(chan struct{}, 4)}
+	h := NewAsyncHandler(rec, AsyncOptions{QueueSize: 1, Policy: DropNewestPolicy, DropReportInterval: 10 * time.Millisecond})
+
+	handleMessage(t, h, "one")
+	<-rec.started
+	handleMessage(t, h, "two")
+	handleMessage(t, h, "three")
+	// The report is written by the report goroutine while the worker is still blocked.
+	<-rec.started
+	close(rec.block)
+	assert.NoError(t, h.Close())
+
+	assert.Contains(t, sprintln(rec.Messages()), "eslog: dropped log records")
+}
+
+func TestAsyncHandlerClosed(t *testing.T) {
+	h := NewAsyncHandler(&recordingHandler{}, AsyncOptions{})
+	assert.NoError(t, h.Close())
+	assert.NoError(t, h.Close())
+
+	err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "closed", 0))
+	assert.Equal(t, ErrHandlerClosed, err)
+}
+
+func TestAsyncSinkFlushedByFatal(t *testing.T) {
+	if os.Getenv("TEST_ASYNC_FATAL") == "1" {
+		l := New(&Config{Sinks: []Sink{{Writer: os.Stdout, Async: &AsyncOptions{}}}})
+		for range 100 {
+			l.Info("queued message")
+		}
+		l.Fatal("async fatal")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestAsyncSinkFlushedByFatal")
+	cmd.Env = append(os.Environ(), "TEST_ASYNC_FATAL=1")
+	out, err := cmd.CombinedOutput()
+
+	assert.IsError(t, err)
+	assert.Contains(t, string(out), "async fatal")
+}
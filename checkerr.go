@@ -0,0 +1,86 @@
+package eslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// CheckErr logs msg and args at [LevelError] together with the attribute created by
+// Err(err) if err is not nil. Attributes attached to err by WrapErr are added, too. It
+// reports whether err is not nil:
+//
+//	if eslog.CheckErr(err, "sending report", "id", id) {
+//		return
+//	}
+func CheckErr(err error, msg string, args ...any) bool {
+	return Logger.CheckErrLevel(slog.LevelError, err, msg, args...)
+}
+
+// CheckErr logs msg and args at [LevelError] together with the attribute created by
+// Err(err) if err is not nil. It reports whether err is not nil.
+func (l eSlogLogger) CheckErr(err error, msg string, args ...any) bool {
+	return l.CheckErrLevel(slog.LevelError, err, msg, args...)
+}
+
+// CheckErrLevel is like CheckErr but logs at level.
+func CheckErrLevel(level slog.Level, err error, msg string, args ...any) bool {
+	return Logger.CheckErrLevel(level, err, msg, args...)
+}
+
+// CheckErrLevel is like CheckErr but logs at level.
+func (l eSlogLogger) CheckErrLevel(level slog.Level, err error, msg string, args ...any) bool {
+	if err == nil {
+		return false
+	}
+	l.logErr(level, err, msg, args...)
+	return true
+}
+
+// LogClose closes c and logs msg and args at [LevelError] if closing fails. It is meant to
+// be deferred:
+//
+//	defer eslog.LogClose(f, "closing file", "path", path)
+func LogClose(c io.Closer, msg string, args ...any) {
+	Logger.LogCloseLevel(slog.LevelError, c, msg, args...)
+}
+
+// LogClose closes c and logs msg and args at [LevelError] if closing fails.
+func (l eSlogLogger) LogClose(c io.Closer, msg string, args ...any) {
+	l.LogCloseLevel(slog.LevelError, c, msg, args...)
+}
+
+// LogCloseLevel is like LogClose but logs at level.
+func LogCloseLevel(level slog.Level, c io.Closer, msg string, args ...any) {
+	Logger.LogCloseLevel(level, c, msg, args...)
+}
+
+// LogCloseLevel is like LogClose but logs at level.
+func (l eSlogLogger) LogCloseLevel(level slog.Level, c io.Closer, msg string, args ...any) {
+	if err := c.Close(); err != nil {
+		l.logErr(level, err, msg, args...)
+	}
+}
+
+// Must returns v if err is nil. Otherwise it logs err at [LevelFatal] and calls
+// os.Exit(1):
+//
+//	cfg := eslog.Must(loadConfig(path))
+func Must[T any](v T, err error) T {
+	if err != nil {
+		Logger.logErr(LevelFatal, err, err.Error())
+		Logger.exit()
+	}
+	return v
+}
+
+// logErr logs msg and args at level together with the attribute created by Err(err) and
+// the attributes attached to the errors by WrapErr.
+func (l eSlogLogger) logErr(level slog.Level, err error, msg string, args ...any) {
+	ctx := context.Background()
+	if !l.Handler().Enabled(ctx, level) {
+		return
+	}
+	args = append(args[:len(args):len(args)], Err(err))
+	l.log(ctx, level, msg, append(args, errorArgAttrs(args)...)...)
+}
@@ -0,0 +1,77 @@
+package eslog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+type failingCloser struct {
+	err error
+}
+
+func (c failingCloser) Close() error {
+	return c.err
+}
+
+func TestCheckErr(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(&Config{out: buf})
+
+	assert.Equal(t, false, l.CheckErr(nil, "sending report"))
+	assert.Equal(t, "", buf.String())
+
+	err := WrapErr(os.ErrDeadlineExceeded, "posting", "url", "http://example.com")
+	assert.Equal(t, true, l.CheckErr(err, "sending report", "id", 7))
+	assert.Contains(t, buf.String(), `level=ERROR msg="sending report" id=7 error.message="posting: i/o timeout"`)
+	assert.Contains(t, buf.String(), `url=http://example.com`)
+
+	buf.Reset()
+	assert.Equal(t, true, l.CheckErrLevel(slog.LevelWarn, os.ErrClosed, "retrying"))
+	assert.Contains(t, buf.String(), `level=WARN msg=retrying error.message="file already closed"`)
+
+	buf.Reset()
+	logLevel.Set(slog.LevelError)
+	defer logLevel.Set(slog.LevelDebug)
+	assert.Equal(t, true, l.CheckErrLevel(slog.LevelWarn, os.ErrClosed, "retrying"))
+	assert.Equal(t, "", buf.String())
+}
+
+func TestLogClose(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(&Config{out: buf})
+
+	l.LogClose(failingCloser{}, "closing file")
+	assert.Equal(t, "", buf.String())
+
+	l.LogClose(failingCloser{errors.New("disk full")}, "closing file", "path", "/tmp/out")
+	assert.Contains(t, buf.String(), `level=ERROR msg="closing file" path=/tmp/out error.message="disk full"`)
+
+	buf.Reset()
+	l.LogCloseLevel(slog.LevelInfo, failingCloser{errors.New("disk full")}, "closing file")
+	assert.Contains(t, buf.String(), `level=INFO msg="closing file" error.message="disk full"`)
+}
+
+func TestMust(t *testing.T) {
+	if os.Getenv("TEST_MUST") == "1" {
+		Must(os.ReadFile("/does/not/exist"))
+		return
+	}
+
+	assert.Equal(t, 42, Must(42, nil))
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestMust")
+	cmd.Env = append(os.Environ(), "TEST_MUST=1")
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	assert.Equal(t, true, errors.As(err, &exitErr))
+	assert.Equal(t, 1, exitErr.ExitCode())
+	assert.Contains(t, string(out), `level=FATAL msg="open /does/not/exist: no such file or directory" error.message=`)
+	assert.Contains(t, string(out), `error.type=*fs.PathError`)
+}
@@ -0,0 +1,109 @@
+package eslog
+
+import (
+	"log/slog"
+	"time"
+)
+
+// cloudWatchHandlerOptions returns opts with a ReplaceAttr writing the fields of the JSON
+// log format of AWS Lambda: timestamp, level and message. Levels are mapped to TRACE,
+// DEBUG, INFO, WARN, ERROR and FATAL.
+func cloudWatchHandlerOptions(opts *slog.HandlerOptions) *slog.HandlerOptions {
+	o := *opts
+	replace := opts.ReplaceAttr
+
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return callReplaceAttr(replace, groups, a)
+		}
+		switch a.Key {
+		case slog.TimeKey:
+			a.Key = "timestamp"
+		case slog.LevelKey:
+			level, ok := a.Value.Any().(slog.Level)
+			if !ok {
+				// An attribute of the record named "level".
+				return callReplaceAttr(replace, groups, a)
+			}
+			if level == LevelPrint {
+				return slog.Attr{}
+			}
+			return slog.String("level", cloudWatchLevel(level))
+		case slog.MessageKey:
+			a.Key = "message"
+		default:
+			return callReplaceAttr(replace, groups, a)
+		}
+		return a
+	}
+	return &o
+}
+
+// cloudWatchLevel maps level to the next lower level of the AWS Lambda log levels.
+func cloudWatchLevel(level slog.Level) string {
+	switch {
+	case level >= LevelFatal:
+		return "FATAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARN"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	case level >= slog.LevelDebug:
+		return "DEBUG"
+	default:
+		return "TRACE"
+	}
+}
+
+// EMFMetric is a metric published with EMF.
+type EMFMetric struct {
+	Name string
+	// Unit is a CloudWatch unit like "Milliseconds" or "Count". It is optional.
+	Unit  string
+	Value float64
+}
+
+// emfMetadata is the "_aws" member of the CloudWatch Embedded Metric Format.
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+type emfDirective struct {
+	Namespace  string                `json:"Namespace"`
+	Dimensions [][]string            `json:"Dimensions"`
+	Metrics    []emfMetricDefinition `json:"Metrics"`
+}
+
+type emfMetricDefinition struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit,omitempty"`
+}
+
+// EMF returns an attribute which turns a record written as JSON into a CloudWatch Embedded
+// Metric Format event, so CloudWatch extracts the metrics from the log. dimensions are the
+// keys of attributes of the record used as metric dimensions. EMF must not be used in a
+// group, because CloudWatch expects the metadata and the metrics at the top level.
+//
+//	eslog.Logger.Info("request", "route", "/users", eslog.EMF("api", []string{"route"},
+//		eslog.EMFMetric{Name: "latency", Unit: "Milliseconds", Value: 12}))
+func EMF(namespace string, dimensions []string, metrics ...EMFMetric) slog.Attr {
+	definitions := make([]emfMetricDefinition, 0, len(metrics))
+	attrs := make([]any, 0, len(metrics)+1)
+	for _, m := range metrics {
+		definitions = append(definitions, emfMetricDefinition{Name: m.Name, Unit: m.Unit})
+		attrs = append(attrs, slog.Float64(m.Name, m.Value))
+	}
+	metadata := emfMetadata{
+		Timestamp: time.Now().UnixMilli(),
+		CloudWatchMetrics: []emfDirective{{
+			Namespace:  namespace,
+			Dimensions: [][]string{append([]string{}, dimensions...)},
+			Metrics:    definitions,
+		}},
+	}
+	// The group without key is inlined by the JSON handler.
+	return slog.Group("", append([]any{slog.Any("_aws", metadata)}, attrs...)...)
+}
@@ -0,0 +1,64 @@
+package eslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestCloudWatchFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(LevelTrace)
+	l := New(&Config{Format: CloudWatchFormat, out: buf})
+
+	l.Warn("slow request", "route", "/users",
+		EMF("api", []string{"route"}, EMFMetric{Name: "latency", Unit: "Milliseconds", Value: 12.5}, EMFMetric{Name: "requests", Value: 1}))
+
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	_, err := time.Parse(time.RFC3339Nano, m["timestamp"].(string))
+	assert.NoError(t, err)
+	aws := m["_aws"].(map[string]any)
+	assert.Equal(t, true, aws["Timestamp"].(float64) > 0)
+	delete(m, "timestamp")
+	delete(aws, "Timestamp")
+
+	expected := map[string]any{
+		"level":   "WARN",
+		"message": "slow request",
+		"route":   "/users",
+		"_aws": map[string]any{
+			"CloudWatchMetrics": []any{map[string]any{
+				"Namespace":  "api",
+				"Dimensions": []any{[]any{"route"}},
+				"Metrics": []any{
+					map[string]any{"Name": "latency", "Unit": "Milliseconds"},
+					map[string]any{"Name": "requests"},
+				},
+			}},
+		},
+		"latency":  12.5,
+		"requests": float64(1),
+	}
+	assert.Equal(t, expected, m)
+}
+
+func TestCloudWatchLevel(t *testing.T) {
+	tests := map[slog.Level]string{
+		LevelTrace:          "TRACE",
+		slog.LevelDebug - 1: "TRACE",
+		slog.LevelDebug:     "DEBUG",
+		slog.LevelInfo + 2:  "INFO",
+		slog.LevelWarn:      "WARN",
+		slog.LevelError:     "ERROR",
+		LevelFatal:          "FATAL",
+		LevelFatal + 4:      "FATAL",
+	}
+	for level, expected := range tests {
+		assert.Equal(t, expected, cloudWatchLevel(level))
+	}
+}
@@ -0,0 +1,71 @@
+// Command eslog-flightrecorder prints the records of an eslog flight recorder file, e.g.
+// after the process which wrote it crashed.
+//
+// Usage:
+//
+//	eslog-flightrecorder [-format text|json|...] [-n count] [-level level] file
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/steffakasid/eslog"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format: text, json, ecs, gcp or cloudwatch")
+	n := flag.Int("n", 0, "print only the last n records, 0 prints all")
+	level := flag.String("level", "trace", "minimum level of printed records")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *format, *n, *level); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(path, formatName string, n int, levelName string) error {
+	format, err := eslog.ParseFormat(formatName)
+	if err != nil {
+		return err
+	}
+	minLevel, err := eslog.ParseText(levelName)
+	if err != nil {
+		return err
+	}
+	records, err := eslog.ReadFlightRecorder(path)
+	if err != nil {
+		return err
+	}
+
+	var filtered []slog.Record
+	for _, r := range records {
+		if r.Level >= minLevel {
+			filtered = append(filtered, r)
+		}
+	}
+	if n > 0 && len(filtered) > n {
+		filtered = filtered[len(filtered)-n:]
+	}
+
+	// Handle doesn't check the level of the Logger, so all records are written.
+	h := eslog.New(&eslog.Config{Format: format}).Handler()
+	for _, r := range filtered {
+		if err := h.Handle(context.Background(), r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,12 @@ type Format int
 const (
 	TextFormat Format = iota
 	JSONFormat
+	// ECSFormat writes JSON using the Elastic Common Schema.
+	ECSFormat
+	// GCPFormat writes JSON with the fields of Google Cloud Logging like severity.
+	GCPFormat
+	// CloudWatchFormat writes JSON with the fields of the AWS Lambda JSON log format.
+	CloudWatchFormat
 )
 
 // String returns the string representation of Format.
@@ -19,6 +25,12 @@ func (f Format) String() string {
 	switch f {
 	case JSONFormat:
 		return "json"
+	case ECSFormat:
+		return "ecs"
+	case GCPFormat:
+		return "gcp"
+	case CloudWatchFormat:
+		return "cloudwatch"
 	case TextFormat:
 		return "text"
 	default:
@@ -34,13 +46,69 @@ func ParseFormat(format string) (Format, error) {
 		return JSONFormat, nil
 	case "text":
 		return TextFormat, nil
+	case "ecs":
+		return ECSFormat, nil
+	case "gcp":
+		return GCPFormat, nil
+	case "cloudwatch":
+		return CloudWatchFormat, nil
 	default:
 		return TextFormat, fmt.Errorf("invalid format: %s", format)
 	}
 }
 
+// newFormatHandler creates the slog.Handler writing format to w.
+func newFormatHandler(format Format, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	switch format {
+	case JSONFormat:
+		return slog.NewJSONHandler(w, opts)
+	case ECSFormat:
+		return newECSHandler(w, opts)
+	case GCPFormat:
+		return slog.NewJSONHandler(w, gcpHandlerOptions(opts))
+	case CloudWatchFormat:
+		return slog.NewJSONHandler(w, cloudWatchHandlerOptions(opts))
+	default:
+		return slog.NewTextHandler(w, opts)
+	}
+}
+
 type Config struct {
 	Level  slog.Level // Log level: debug, info, warn, error, fatal
-	Format Format // Log format: TextFormat or JSONFormat
-    out io.Writer
-}
\ No newline at end of file
+	Format Format     // Log format, e.g. TextFormat or JSONFormat
+	// OnPrintError is called if Print, Printf or Println fail to write. Defaults to
+	// PrintErrorOnce(os.Stderr).
+	OnPrintError func(err error)
+	// AddSource adds the source location of the call site to each record. The location
+	// points to the caller of the eslog function, not to eslog itself.
+	AddSource bool
+	// SourceRoot is used to make the file of the source location relative, e.g. to the
+	// module root. Files outside of SourceRoot are logged with their full path.
+	SourceRoot string
+	// PriorityPrefix prefixes each record with the syslog severity of its level like "<3>",
+	// so systemd assigns the priority to records written to stdout or stderr of a service.
+	// Print output is not prefixed.
+	PriorityPrefix bool
+	// Sinks configures multiple outputs with their own level, format and writer. If Sinks
+	// are set Format and the output set by SetOutput are not used.
+	Sinks []Sink
+	// Sampling limits the number of records with the same level and message per interval.
+	Sampling *SamplingOptions
+	// Dedup collapses identical consecutive records into one record and a summary with the
+	// number of repetitions.
+	Dedup *DedupOptions
+	// FingersCrossed buffers the most recent records below the level of the Logger and
+	// writes them when a record at or above the threshold is logged.
+	FingersCrossed *FingersCrossedOptions
+	// FlightRecorder additionally writes all records at or above its own level to a
+	// memory-mapped file, so they can be read after a crash.
+	FlightRecorder *FlightRecorder
+	// Redact masks sensitive attributes and values in records and Print output.
+	Redact *RedactOptions
+	// ErrorRules change the level of records with an error attribute, e.g. one created by
+	// Err or LogIfError. The first rule matching an error of the record is applied, so
+	// errors like context.Canceled can be logged at debug level or dropped. Rules are only
+	// applied to records which are enabled at their original level.
+	ErrorRules []ErrorRule
+	out        io.Writer
+}
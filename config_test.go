@@ -1,7 +1,11 @@
 package eslog
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
 )
 
 func TestFormat_String(t *testing.T) {
@@ -12,6 +16,9 @@ func TestFormat_String(t *testing.T) {
 	}{
 		{"JSON format", JSONFormat, "json"},
 		{"TEXT format", TextFormat, "text"},
+		{"ECS format", ECSFormat, "ecs"},
+		{"GCP format", GCPFormat, "gcp"},
+		{"CloudWatch format", CloudWatchFormat, "cloudwatch"},
 		{"Unknown format", Format(99), "unknown"},
 	}
 
@@ -34,6 +41,9 @@ func TestParseFormat(t *testing.T) {
 	}{
 		{"json lowercase", "json", JSONFormat, false},
 		{"text lowercase", "text", TextFormat, false},
+		{"ecs lowercase", "ecs", ECSFormat, false},
+		{"gcp lowercase", "gcp", GCPFormat, false},
+		{"cloudwatch lowercase", "cloudwatch", CloudWatchFormat, false},
 		{"invalid format", "invalid", TextFormat, true},
 		{"empty string", "", TextFormat, true},
 	}
@@ -56,3 +66,17 @@ func TestParseFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestLevelAttribute(t *testing.T) {
+	for _, format := range []Format{TextFormat, JSONFormat, ECSFormat, GCPFormat, CloudWatchFormat} {
+		t.Run(format.String(), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := New(&Config{Format: format, out: buf})
+
+			// An attribute named like the level key must not be taken for the level.
+			l.Info("alert raised", "level", "high")
+			assert.Contains(t, buf.String(), "high")
+			assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package eslog
 
-import "fmt"
+import (
+	"context"
+	"log/slog"
+)
 
-// Debugf logs at [LevelDebug]. Multiple args are joined with "  ".
+// Debug logs at [LevelDebug]. The args are handled as key-value pairs or attributes like
+// slog.Logger.Debug does.
 func Debug(msg string, args ...any) {
-	Logger.Debug(msg, args...)
+	Logger.log(context.Background(), slog.LevelDebug, msg, args...)
 }
 
 // Debugf logs at [LevelDebug]. The function uses fmt.Sprintf with given format and args
@@ -13,17 +17,20 @@ func Debugf(format string, args ...any) {
 	Logger.Debugf(format, args...)
 }
 
-func DebugLn(msg string, args ...any) {
-	Logger.DebugLn(msg, args...)
+// DebugLn logs at [LevelDebug]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func DebugLn(args ...any) {
+	Logger.DebugLn(args...)
 }
 
 // Debugf logs at [LevelDebug]. The function uses fmt.Sprintf with given format and args
 // and log it.
 func (l eSlogLogger) Debugf(format string, args ...any) {
-	l.Debug(fmt.Sprintf(format, args...))
+	l.logf(slog.LevelDebug, format, args...)
 }
 
-func (l eSlogLogger) DebugLn(msg string, args ...any) {
-	args = append(args, "\n")
-	Logger.Debug(msg, args...)
+// DebugLn logs at [LevelDebug]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func (l eSlogLogger) DebugLn(args ...any) {
+	l.logln(slog.LevelDebug, args...)
 }
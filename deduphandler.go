@@ -0,0 +1,162 @@
+package eslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DedupOptions configures the suppression of repeated records with Config.Dedup.
+type DedupOptions struct {
+	// Window is the time after the first of identical consecutive records in which
+	// repetitions are collapsed. Defaults to 10 seconds.
+	Window time.Duration
+}
+
+// defaultDedupWindow is used if DedupOptions.Window is not set.
+const defaultDedupWindow = 10 * time.Second
+
+// dedupPending is the last logged record whose repetitions are counted.
+type dedupPending struct {
+	ctx      context.Context
+	h        slog.Handler
+	r        slog.Record
+	key      string
+	repeated uint64
+	// generation identifies the record for its window timer.
+	generation uint64
+}
+
+// dedupState is shared between a dedupHandler and the handlers derived from it.
+type dedupState struct {
+	window time.Duration
+
+	mu         sync.Mutex
+	last       *dedupPending
+	generation uint64
+	timer      *time.Timer
+}
+
+// dedupHandler collapses identical consecutive records (same level, message and attributes)
+// within a window. The first record is logged immediately. If it was repeated, a copy of
+// it with the attribute "repeated" is logged when the window ends or a different record
+// is logged.
+type dedupHandler struct {
+	h slog.Handler
+	// prefix describes the attributes and groups added by WithAttrs and WithGroup, so only
+	// records logged with the same attributes are identical.
+	prefix string
+	state  *dedupState
+}
+
+func newDedupHandler(h slog.Handler, opts DedupOptions) *dedupHandler {
+	if opts.Window <= 0 {
+		opts.Window = defaultDedupWindow
+	}
+	return &dedupHandler{h: h, state: &dedupState{window: opts.Window}}
+}
+
+func (d *dedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return d.h.Enabled(ctx, level)
+}
+
+func (d *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	s := d.state
+	if r.Level == LevelPrint || r.Level >= LevelFatal {
+		s.flush()
+		return d.h.Handle(ctx, r)
+	}
+
+	key := d.key(r)
+	s.mu.Lock()
+	if s.last != nil && s.last.key == key {
+		s.last.repeated++
+		s.mu.Unlock()
+		return nil
+	}
+	summary := s.takeSummary()
+	s.generation++
+	s.last = &dedupPending{ctx: context.WithoutCancel(ctx), h: d.h, r: r.Clone(), key: key, generation: s.generation}
+	generation := s.generation
+	s.timer = time.AfterFunc(s.window, func() { s.flushGeneration(generation) })
+	s.mu.Unlock()
+
+	summary.log()
+	return d.h.Handle(ctx, r)
+}
+
+func (d *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dedupHandler{h: d.h.WithAttrs(attrs), prefix: d.prefix + fmt.Sprint(attrs), state: d.state}
+}
+
+func (d *dedupHandler) WithGroup(name string) slog.Handler {
+	return &dedupHandler{h: d.h.WithGroup(name), prefix: d.prefix + "." + name, state: d.state}
+}
+
+// Flush logs the summary of the pending record and flushes the wrapped handler.
+func (d *dedupHandler) Flush() error {
+	d.state.flush()
+	return flushHandler(d.h)
+}
+
+func (d *dedupHandler) Close() error {
+	d.state.flush()
+	return closeHandler(d.h)
+}
+
+// key identifies identical records.
+func (d *dedupHandler) key(r slog.Record) string {
+	b := &strings.Builder{}
+	_, _ = fmt.Fprintf(b, "%s|%d|%q", d.prefix, r.Level, r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		_, _ = fmt.Fprintf(b, "|%s", a)
+		return true
+	})
+	return b.String()
+}
+
+// flush logs the summary of the pending record, if it was repeated.
+func (s *dedupState) flush() {
+	s.mu.Lock()
+	summary := s.takeSummary()
+	s.mu.Unlock()
+	summary.log()
+}
+
+// flushGeneration logs the summary of the pending record when its window ended, unless it
+// was already replaced by another record.
+func (s *dedupState) flushGeneration(generation uint64) {
+	s.mu.Lock()
+	if s.last == nil || s.last.generation != generation {
+		s.mu.Unlock()
+		return
+	}
+	summary := s.takeSummary()
+	s.mu.Unlock()
+	summary.log()
+}
+
+// takeSummary removes the pending record and returns it. s.mu must be held.
+func (s *dedupState) takeSummary() *dedupPending {
+	last := s.last
+	s.last = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return last
+}
+
+// log logs the summary of p if it was repeated. A nil p is ignored.
+func (p *dedupPending) log() {
+	if p == nil || p.repeated == 0 {
+		return
+	}
+	r := p.r.Clone()
+	r.Time = time.Now()
+	r.AddAttrs(slog.Uint64("repeated", p.repeated))
+	_ = p.h.Handle(p.ctx, r)
+}
@@ -0,0 +1,80 @@
+package eslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestDedupHandler(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newDedupHandler(rec, DedupOptions{Window: time.Hour})
+
+	for range 5 {
+		handleMessage(t, h, "retrying")
+	}
+	handleMessage(t, h, "connected")
+
+	assert.Equal(t, []string{"retrying", "retrying", "connected"}, rec.Messages())
+	assert.Equal(t, []slog.Attr{slog.Uint64("repeated", 4)}, rec.attrs)
+}
+
+func TestDedupHandlerComparesAttrsAndLevel(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newDedupHandler(rec, DedupOptions{Window: time.Hour})
+
+	records := []slog.Record{
+		slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0),
+		slog.NewRecord(time.Now(), slog.LevelWarn, "msg", 0),
+		slog.NewRecord(time.Now(), slog.LevelWarn, "msg", 0),
+	}
+	records[2].AddAttrs(slog.Int("attempt", 2))
+	for _, r := range records {
+		assert.NoError(t, h.Handle(context.Background(), r))
+	}
+	assert.NoError(t, h.WithAttrs([]slog.Attr{slog.String("a", "b")}).Handle(context.Background(), records[2]))
+
+	assert.Equal(t, 4, len(rec.Messages()))
+	assert.NoError(t, h.Flush())
+	assert.Equal(t, 4, len(rec.Messages()))
+}
+
+func TestDedupHandlerWindow(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newDedupHandler(rec, DedupOptions{Window: 20 * time.Millisecond})
+
+	handleMessage(t, h, "retrying")
+	handleMessage(t, h, "retrying")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.Messages()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("summary was not logged after the window ended")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// After the window the next record is logged again.
+	handleMessage(t, h, "retrying")
+	assert.Equal(t, []string{"retrying", "retrying", "retrying"}, rec.Messages())
+	assert.NoError(t, h.Close())
+}
+
+func TestDedupConfig(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{Dedup: &DedupOptions{Window: time.Hour}, out: buf})
+
+	for range 3 {
+		l.Warn("disk almost full", "usage", 95)
+	}
+	l.Print("done\n")
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "disk almost full"))
+	assert.Contains(t, buf.String(), "msg=\"disk almost full\" usage=95 repeated=2\ndone\n")
+}
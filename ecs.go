@@ -0,0 +1,120 @@
+package eslog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ecsVersion is the version of the Elastic Common Schema written as ecs.version.
+const ecsVersion = "8.11.0"
+
+// ecsHandler writes records as Elastic Common Schema JSON lines. Attributes are written
+// with dotted keys, so groups map to ECS field sets like "http.request.method". The first
+// error attribute is written as error.message, error.type and error.stack_trace.
+type ecsHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	opts  slog.HandlerOptions
+	attrs attrState
+}
+
+func newECSHandler(w io.Writer, opts *slog.HandlerOptions) *ecsHandler {
+	h := &ecsHandler{w: w, mu: &sync.Mutex{}}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *ecsHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *ecsHandler) Handle(_ context.Context, r slog.Record) error {
+	b := append(make([]byte, 0, 512), '{')
+	if !r.Time.IsZero() {
+		b = append(b, `"@timestamp":`...)
+		b = appendJSONString(b, r.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+		b = append(b, ',')
+	}
+	b = append(b, `"log.level":`...)
+	b = appendJSONString(b, strings.ToLower(levelName(r.Level)))
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, r.Message)
+	if h.opts.AddSource && r.PC != 0 {
+		b = h.appendOrigin(b, r.PC)
+	}
+	b = append(b, `,"ecs.version":"`+ecsVersion+`"`...)
+
+	hasError := false
+	for _, f := range h.attrs.recordFields(r) {
+		if err, ok := f.value.Any().(error); ok && !hasError {
+			b = appendECSError(b, err)
+			hasError = true
+			continue
+		}
+		b = append(b, ',')
+		b = appendJSONString(b, f.name("."))
+		b = append(b, ':')
+		b = appendJSONValue(b, f.value)
+	}
+	b = append(b, '}', '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(b)
+	return err
+}
+
+func (h *ecsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ecsHandler{w: h.w, mu: h.mu, opts: h.opts, attrs: h.attrs.withAttrs(attrs)}
+}
+
+func (h *ecsHandler) WithGroup(name string) slog.Handler {
+	return &ecsHandler{w: h.w, mu: h.mu, opts: h.opts, attrs: h.attrs.withGroup(name)}
+}
+
+// appendOrigin appends the source location as log.origin fields. The file is passed through
+// ReplaceAttr, e.g. to make it relative to Config.SourceRoot.
+func (h *ecsHandler) appendOrigin(b []byte, pc uintptr) []byte {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	src := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+	if h.opts.ReplaceAttr != nil {
+		a := h.opts.ReplaceAttr(nil, slog.Any(slog.SourceKey, src))
+		if replaced, ok := a.Value.Any().(*slog.Source); ok {
+			src = replaced
+		}
+	}
+
+	b = append(b, `,"log.origin.file.name":`...)
+	b = appendJSONString(b, src.File)
+	b = append(b, `,"log.origin.file.line":`...)
+	b = strconv.AppendInt(b, int64(src.Line), 10)
+	b = append(b, `,"log.origin.function":`...)
+	return appendJSONString(b, src.Function)
+}
+
+// appendECSError appends err as ECS error fields. The stack trace is taken from the %+v
+// formatting of err, which errors with a stack trace like those of github.com/pkg/errors
+// implement.
+func appendECSError(b []byte, err error) []byte {
+	b = append(b, `,"error.message":`...)
+	b = appendJSONString(b, err.Error())
+	b = append(b, `,"error.type":`...)
+	b = appendJSONString(b, fmt.Sprintf("%T", err))
+	if stack := fmt.Sprintf("%+v", err); stack != err.Error() {
+		b = append(b, `,"error.stack_trace":`...)
+		b = appendJSONString(b, stack)
+	}
+	return b
+}
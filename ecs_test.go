@@ -0,0 +1,83 @@
+package eslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// stackError formats with a stack trace for %+v like the errors of github.com/pkg/errors.
+type stackError struct{ msg string }
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprintf(s, "%s\nmain.main\n\t/app/main.go:10", e.msg)
+		return
+	}
+	_, _ = fmt.Fprint(s, e.msg)
+}
+
+func decodeECS(t *testing.T, b []byte) map[string]any {
+	t.Helper()
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
+
+func TestECSHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := newECSHandler(buf, &slog.HandlerOptions{Level: LevelTrace})
+
+	r := slog.NewRecord(time.Date(2026, 1, 2, 3, 4, 5, 6000000, time.FixedZone("CET", 3600)), LevelFatal, "request failed", 0)
+	r.Add("method", "GET", "status", 500, "cached", false, "took", time.Millisecond,
+		"err", &stackError{"connection refused"}, "cause", errors.New("timeout"))
+	handler := h.WithAttrs([]slog.Attr{slog.String("service.name", "api")}).WithGroup("http.request")
+	assert.NoError(t, handler.Handle(t.Context(), r))
+
+	expected := map[string]any{
+		"@timestamp":          "2026-01-02T02:04:05.006Z",
+		"log.level":           "fatal",
+		"message":             "request failed",
+		"ecs.version":         ecsVersion,
+		"service.name":        "api",
+		"http.request.method": "GET",
+		"http.request.status": float64(500),
+		"http.request.cached": false,
+		"http.request.took":   float64(time.Millisecond),
+		"error.message":       "connection refused",
+		"error.type":          "*eslog.stackError",
+		"error.stack_trace":   "connection refused\nmain.main\n\t/app/main.go:10",
+		"http.request.cause":  "timeout",
+	}
+	assert.Equal(t, expected, decodeECS(t, buf.Bytes()))
+}
+
+func TestECSFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	_, file, _, _ := runtime.Caller(0)
+	l := New(&Config{Format: ECSFormat, AddSource: true, SourceRoot: file[:len(file)-len("ecs_test.go")], out: buf})
+
+	l.Error("failed", "err", errors.New("boom"))
+	_, _, line, _ := runtime.Caller(0)
+	l.Print("print\n")
+
+	lines := bytes.SplitAfter(buf.Bytes(), []byte("\n"))
+	m := decodeECS(t, lines[0])
+	assert.Equal(t, any("error"), m["log.level"])
+	assert.Equal(t, any("boom"), m["error.message"])
+	assert.Equal(t, any("*errors.errorString"), m["error.type"])
+	assert.Equal(t, nil, m["error.stack_trace"])
+	assert.Equal(t, any("ecs_test.go"), m["log.origin.file.name"])
+	assert.Equal(t, any(float64(line-1)), m["log.origin.file.line"])
+	assert.Equal(t, "print\n", string(lines[1]))
+}
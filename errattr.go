@@ -0,0 +1,150 @@
+package eslog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrorKey is the key of the attributes created by Err.
+const ErrorKey = "error"
+
+// maxErrorDepth limits the depth of the error chain logged by Err.
+const maxErrorDepth = 16
+
+// StackTracer is implemented by errors which carry the stack of the place where they were
+// created, like the errors returned by WithStack.
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// Err returns an attribute with key "error" describing err as group:
+//
+//   - message: err.Error()
+//   - type: the Go type of err
+//   - cause: the error returned by Unwrap() error, described the same way
+//   - causes: the errors returned by Unwrap() []error (e.g. by errors.Join), keyed by
+//     their index
+//   - stack_trace: the stack of the first error in the chain implementing StackTracer
+//
+// The keys match the error fields of the Elastic Common Schema. If err is nil the
+// attribute is empty and not logged.
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any(ErrorKey, errorValue{err: err})
+}
+
+// errorValue resolves to the group described by Err.
+type errorValue struct {
+	err error
+}
+
+// LogValue implements slog.LogValuer.
+func (v errorValue) LogValue() slog.Value {
+	attrs := errorAttrs(v.err, 0)
+	var st StackTracer
+	if errors.As(v.err, &st) {
+		if stack := formatStack(st.StackTrace()); stack != "" {
+			attrs = append(attrs, slog.String("stack_trace", stack))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// errorAttrs returns the message, the type and the causes of err.
+func errorAttrs(err error, depth int) []slog.Attr {
+	// The errors added by WithStack and Errorw are transparent, their stack and attributes
+	// are logged separately.
+	for {
+		if se, ok := err.(*withStackError); ok {
+			err = se.err
+		} else if we, ok := err.(*wrapError); ok && we.msg == "" {
+			err = we.err
+		} else {
+			break
+		}
+	}
+	attrs := []slog.Attr{slog.String("message", err.Error())}
+	// The type of the layers added by WrapErr is internal, they only add a message.
+	if _, ok := err.(*wrapError); !ok {
+		attrs = append(attrs, slog.String("type", fmt.Sprintf("%T", err)))
+	}
+	if depth >= maxErrorDepth {
+		return attrs
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			attrs = append(attrs, slog.Any("cause", slog.GroupValue(errorAttrs(cause, depth+1)...)))
+		}
+	case interface{ Unwrap() []error }:
+		var causes []slog.Attr
+		for i, cause := range u.Unwrap() {
+			if cause != nil {
+				causes = append(causes, slog.Any(strconv.Itoa(i), slog.GroupValue(errorAttrs(cause, depth+1)...)))
+			}
+		}
+		if len(causes) > 0 {
+			attrs = append(attrs, slog.Any("causes", slog.GroupValue(causes...)))
+		}
+	}
+	return attrs
+}
+
+// formatStack formats pcs like the stack of a goroutine in a panic:
+// the function followed by the file and line on a tab indented line.
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// withStackError is an error with the stack of the place where it was created.
+type withStackError struct {
+	err error
+	pcs []uintptr
+}
+
+// WithStack returns err annotated with the stack of the caller, which Err logs as
+// stack_trace. It returns err unchanged if it is nil or already carries a stack.
+func WithStack(err error) error {
+	var st StackTracer
+	if err == nil || errors.As(err, &st) {
+		return err
+	}
+	var pcs [maxCallerDepth]uintptr
+	// Skip runtime.Callers and WithStack.
+	n := runtime.Callers(2, pcs[:])
+	return &withStackError{err: err, pcs: pcs[:n]}
+}
+
+func (e *withStackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *withStackError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace implements StackTracer.
+func (e *withStackError) StackTrace() []uintptr {
+	return e.pcs
+}
@@ -0,0 +1,110 @@
+package eslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// errJSON returns the JSON encoding of the value of Err(err).
+func errJSON(t *testing.T, err error) string {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	l := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key != ErrorKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	l.Info("", Err(err))
+	return buf.String()
+}
+
+func TestErr(t *testing.T) {
+	pathErr := &os.PathError{Op: "open", Path: "/etc/app.conf", Err: os.ErrNotExist}
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"simple", errors.New("boom"), `{"error":{"message":"boom","type":"*errors.errorString"}}`},
+		{
+			"wrapped", fmt.Errorf("loading config: %w", pathErr),
+			`{"error":{"message":"loading config: open /etc/app.conf: file does not exist","type":"*fmt.wrapError",` +
+				`"cause":{"message":"open /etc/app.conf: file does not exist","type":"*fs.PathError",` +
+				`"cause":{"message":"file does not exist","type":"*errors.errorString"}}}}`,
+		},
+		{
+			"joined", errors.Join(errors.New("a"), nil, errors.New("b")),
+			`{"error":{"message":"a\nb","type":"*errors.joinError","causes":{` +
+				`"0":{"message":"a","type":"*errors.errorString"},"1":{"message":"b","type":"*errors.errorString"}}}}`,
+		},
+		{"nil", nil, `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected+"\n", errJSON(t, tt.err))
+		})
+	}
+}
+
+func TestErrWithStack(t *testing.T) {
+	err := WithStack(os.ErrClosed)
+	assert.Equal(t, true, errors.Is(err, os.ErrClosed))
+	assert.Equal(t, err, WithStack(fmt.Errorf("closing: %w", err)).(interface{ Unwrap() error }).Unwrap())
+	assert.Equal(t, nil, WithStack(nil))
+
+	var m map[string]map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(errJSON(t, fmt.Errorf("closing: %w", err))), &m))
+	cause := m[ErrorKey]["cause"].(map[string]any)
+	assert.Equal(t, any("*errors.errorString"), cause["type"])
+	assert.Equal(t, nil, cause["cause"])
+	assert.Contains(t, m[ErrorKey]["stack_trace"].(string), "eslog.TestErrWithStack\n\t")
+	assert.Contains(t, m[ErrorKey]["stack_trace"].(string), "errattr_test.go:")
+}
+
+func TestErrAsArg(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(&Config{out: buf})
+
+	l.logArgs(slog.LevelError, "write", "failed", Err(os.ErrClosed))
+	assert.Contains(t, buf.String(), `msg="write failed" error.message="file already closed" error.type=*errors.errorString`)
+
+	buf.Reset()
+	useLogger(t, l)
+	LogIfError(os.ErrPermission, Error)
+	assert.Contains(t, buf.String(), `msg="permission denied" error.message="permission denied"`)
+
+	buf.Reset()
+	args := make([]any, 1, 2)
+	args[0] = "saving failed"
+	LogIfError(os.ErrPermission, Error, args...)
+	assert.Contains(t, buf.String(), `msg="saving failed" error.message="permission denied"`)
+	assert.Equal(t, 1, len(args))
+}
+
+// useLogger makes l the default Logger until the end of the test.
+func useLogger(t *testing.T, l *eSlogLogger) {
+	t.Helper()
+	old := Logger
+	Logger = l
+	t.Cleanup(func() { Logger = old })
+}
+
+func TestLogIfErrorWithPrint(t *testing.T) {
+	buf := &bytes.Buffer{}
+	useLogger(t, New(&Config{out: buf}))
+
+	LogIfError(errors.New("boom"), Print)
+	LogIfError(errors.New("boom"), Print, "saving failed\n")
+	LogIfError(nil, Print, "not printed")
+	assert.Equal(t, "boomsaving failed\n", buf.String())
+}
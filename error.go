@@ -1,13 +1,10 @@
 package eslog
 
-import (
-	"fmt"
-	"strings"
-)
+import "log/slog"
 
 // Error logs at [LevelError]. Multiple args are joined with "  ".
 func Error(args ...any) {
-	Logger.Error(strings.Join(convertAnyToString(args...), " "))
+	Logger.logArgs(slog.LevelError, args...)
 }
 
 // Errorf logs at [LevelError]. The function uses fmt.Sprintf with given format and args
@@ -19,15 +16,17 @@ func Errorf(format string, args ...any) {
 // Errorf logs at [LevelError]. The function uses fmt.Sprintf with given format and args
 // and log it.
 func (l eSlogLogger) Errorf(format string, args ...any) {
-	Logger.Error(fmt.Sprintf(format, args...))
+	l.logf(slog.LevelError, format, args...)
 }
 
-// ErrorLn logs at [LevelError] and appends a newline.
-func ErrorLn(msg string, args ...any) {
-	Logger.ErrorLn(msg, args...)
+// ErrorLn logs at [LevelError]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func ErrorLn(args ...any) {
+	Logger.ErrorLn(args...)
 }
 
-func (l eSlogLogger) ErrorLn(msg string, args ...any) {
-	args = append(args, "\n")
-	Logger.Error(msg, args...)
+// ErrorLn logs at [LevelError]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func (l eSlogLogger) ErrorLn(args ...any) {
+	l.logln(slog.LevelError, args...)
 }
@@ -0,0 +1,129 @@
+package eslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// ErrorRule changes the level of records containing a matching error, see
+// Config.ErrorRules.
+type ErrorRule struct {
+	// Match reports whether the rule applies to an error. Use MatchIs or MatchAs to match
+	// errors with errors.Is and errors.As.
+	Match func(err error) bool
+	// Level is the new level of the record.
+	Level slog.Level
+	// Drop drops the record instead of changing its level.
+	Drop bool
+}
+
+// MatchIs returns a matcher for ErrorRule.Match which reports whether errors.Is(err,
+// target).
+func MatchIs(target error) func(err error) bool {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// MatchAs returns a matcher for ErrorRule.Match which reports whether an error in the chain
+// of err is of type T.
+func MatchAs[T error]() func(err error) bool {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// errorRulesHandler applies the first ErrorRule matching an error attribute of a record.
+type errorRulesHandler struct {
+	h     slog.Handler
+	rules []ErrorRule
+	// errs are the errors added by WithAttrs.
+	errs []error
+}
+
+func newErrorRulesHandler(h slog.Handler, rules []ErrorRule) *errorRulesHandler {
+	return &errorRulesHandler{h: h, rules: rules}
+}
+
+func (eh *errorRulesHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return eh.h.Enabled(ctx, level)
+}
+
+// Handle changes the level of r or drops it if a rule matches one of its errors. Records
+// whose new level isn't enabled are dropped, too.
+func (eh *errorRulesHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == LevelPrint {
+		return eh.h.Handle(ctx, r)
+	}
+	errs := eh.errs
+	r.Attrs(func(a slog.Attr) bool {
+		errs = appendAttrErrors(errs, a.Value)
+		return true
+	})
+	if rule, ok := eh.match(errs); ok {
+		if rule.Drop {
+			return nil
+		}
+		r.Level = rule.Level
+		if !eh.h.Enabled(ctx, r.Level) {
+			return nil
+		}
+	}
+	return eh.h.Handle(ctx, r)
+}
+
+func (eh *errorRulesHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	errs := append([]error{}, eh.errs...)
+	for _, a := range attrs {
+		errs = appendAttrErrors(errs, a.Value)
+	}
+	return &errorRulesHandler{h: eh.h.WithAttrs(attrs), rules: eh.rules, errs: errs}
+}
+
+func (eh *errorRulesHandler) WithGroup(name string) slog.Handler {
+	return &errorRulesHandler{h: eh.h.WithGroup(name), rules: eh.rules, errs: eh.errs}
+}
+
+func (eh *errorRulesHandler) Flush() error {
+	return flushHandler(eh.h)
+}
+
+func (eh *errorRulesHandler) Close() error {
+	return closeHandler(eh.h)
+}
+
+// match returns the first rule matching one of errs.
+func (eh *errorRulesHandler) match(errs []error) (ErrorRule, bool) {
+	if len(errs) == 0 {
+		return ErrorRule{}, false
+	}
+	for _, rule := range eh.rules {
+		for _, err := range errs {
+			if rule.Match(err) {
+				return rule, true
+			}
+		}
+	}
+	return ErrorRule{}, false
+}
+
+// appendAttrErrors appends the errors in v to errs: v itself, the error of an attribute
+// created by Err or the errors in a group.
+func appendAttrErrors(errs []error, v slog.Value) []error {
+	switch v.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		switch a := v.Any().(type) {
+		case errorValue:
+			errs = append(errs, a.err)
+		case error:
+			errs = append(errs, a)
+		}
+	case slog.KindGroup:
+		for _, ga := range v.Group() {
+			errs = appendAttrErrors(errs, ga.Value)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+package eslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestErrorRulesHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	text := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	h := newErrorRulesHandler(text, []ErrorRule{
+		{Match: MatchIs(io.EOF), Drop: true},
+		{Match: MatchAs[*fs.PathError](), Level: slog.LevelWarn},
+		{Match: MatchIs(os.ErrNotExist), Level: slog.LevelInfo},
+	})
+
+	handle := func(h slog.Handler, msg string, attrs ...slog.Attr) {
+		r := slog.NewRecord(time.Now(), slog.LevelError, msg, 0)
+		r.AddAttrs(attrs...)
+		assert.NoError(t, h.Handle(context.Background(), r))
+	}
+	handle(h, "eof", slog.Any("err", io.EOF))
+	handle(h, "wrapped eof", Err(WrapErr(io.EOF, "reading")))
+	// The first matching rule wins.
+	handle(h, "open", slog.Group("req", slog.Any("err", &fs.PathError{Op: "open", Err: os.ErrNotExist})))
+	handle(h, "not exist", slog.Any("err", os.ErrNotExist))
+	handle(h, "other", slog.Any("err", os.ErrClosed))
+	handle(h, "no error", slog.String("err", "EOF"))
+	handle(h.WithAttrs([]slog.Attr{slog.Any("err", io.EOF)}).WithGroup("g"), "with attrs")
+
+	assert.Equal(t, `level=WARN msg=open req.err="open : file does not exist"
+level=INFO msg="not exist" err="file does not exist"
+level=ERROR msg=other err="file already closed"
+level=ERROR msg="no error" err=EOF
+`, buf.String())
+}
+
+func TestErrorRulesConfig(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelInfo)
+	defer logLevel.Set(slog.LevelDebug)
+	l := New(&Config{
+		ErrorRules: []ErrorRule{
+			{Match: MatchIs(context.Canceled), Level: slog.LevelDebug},
+			{Match: MatchIs(context.DeadlineExceeded), Level: slog.LevelWarn},
+		},
+		out: buf,
+	})
+
+	useLogger(t, l)
+	LogIfError(context.Canceled, Error, "request canceled")
+	l.CheckErr(context.DeadlineExceeded, "request timed out")
+	l.Error("query failed", "err", errors.New("syntax error"))
+	l.Print("done\n")
+
+	assert.NotContains(t, buf.String(), "request canceled")
+	assert.Contains(t, buf.String(), `level=WARN msg="request timed out"`)
+	assert.Contains(t, buf.String(), `level=ERROR msg="query failed"`)
+	assert.Contains(t, buf.String(), "done\n")
+}
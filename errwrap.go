@@ -0,0 +1,130 @@
+package eslog
+
+import (
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+// wrapError is an error with attributes attached by WrapErr or Errorw.
+type wrapError struct {
+	err   error
+	msg   string
+	attrs []slog.Attr
+}
+
+// WrapErr returns an error with the message "msg: err.Error()" which wraps err and carries
+// the attributes built from args. args are key-value pairs or slog.Attr values like the
+// args of slog.Logger.Info. When the error is logged with Error, Fatal, LogIfError or
+// the other functions of eslog, the attributes of all wrap layers are added to the record.
+// It returns nil if err is nil.
+func WrapErr(err error, msg string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return &wrapError{err: err, msg: msg, attrs: argsToAttrs(args)}
+}
+
+// Errorw returns err with the attributes built from args attached, without changing the
+// message of err. See WrapErr. It returns nil if err is nil.
+func Errorw(err error, args ...any) error {
+	return WrapErr(err, "", args...)
+}
+
+func (e *wrapError) Error() string {
+	if e.msg == "" {
+		return e.err.Error()
+	}
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// ErrorAttrs returns the attributes attached to err and the errors in its chain by WrapErr
+// and Errorw, from the innermost to the outermost layer. Use it to add them to records
+// which are not logged by eslog, e.g. with slog.Logger.Error.
+func ErrorAttrs(err error) []slog.Attr {
+	return appendErrorAttrs(nil, err, 0)
+}
+
+func appendErrorAttrs(attrs []slog.Attr, err error, depth int) []slog.Attr {
+	if err == nil || depth >= maxErrorDepth {
+		return attrs
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		attrs = appendErrorAttrs(attrs, u.Unwrap(), depth+1)
+	case interface{ Unwrap() []error }:
+		for _, cause := range u.Unwrap() {
+			attrs = appendErrorAttrs(attrs, cause, depth+1)
+		}
+	}
+	if we, ok := err.(*wrapError); ok {
+		attrs = append(attrs, we.attrs...)
+	}
+	return attrs
+}
+
+// errorArgAttrs returns the attributes attached to the errors in args, which are either
+// errors or attributes holding an error like the ones created by Err. An error passed more
+// than once, e.g. as err and Err(err) by LogIfError, contributes its attributes once.
+func errorArgAttrs(args []any) []any {
+	var attrs []any
+	var seen []error
+	for _, arg := range args {
+		var err error
+		switch v := arg.(type) {
+		case error:
+			err = v
+		case slog.Attr:
+			switch a := v.Value.Any().(type) {
+			case errorValue:
+				err = a.err
+			case error:
+				err = a
+			}
+		}
+		if err == nil || containsError(seen, err) {
+			continue
+		}
+		seen = append(seen, err)
+		for _, a := range ErrorAttrs(err) {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// containsError reports whether errs contains err. Only errors which are pointers are
+// compared, by identity, as comparing other errors may panic. Pointers cover the errors
+// created by WrapErr and Errorw.
+func containsError(errs []error, err error) bool {
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Pointer {
+		return false
+	}
+	for _, e := range errs {
+		if ev := reflect.ValueOf(e); ev.Type() == v.Type() && ev.Pointer() == v.Pointer() {
+			return true
+		}
+	}
+	return false
+}
+
+// argsToAttrs converts key-value pairs and slog.Attr values to attributes the same way
+// slog.Logger does.
+func argsToAttrs(args []any) []slog.Attr {
+	if len(args) == 0 {
+		return nil
+	}
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,101 @@
+package eslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// loadUser fails deep down the call stack and attaches context on each layer.
+func loadUser(id int) error {
+	err := Errorw(os.ErrNotExist, "table", "users")
+	err = WrapErr(err, "query", slog.Int("user_id", id))
+	return fmt.Errorf("loading profile: %w", err)
+}
+
+func TestWrapErr(t *testing.T) {
+	assert.Equal(t, nil, WrapErr(nil, "query", "a", 1))
+	assert.Equal(t, nil, Errorw(nil, "a", 1))
+
+	err := loadUser(42)
+	assert.Equal(t, "loading profile: query: file does not exist", err.Error())
+	assert.Equal(t, true, errors.Is(err, os.ErrNotExist))
+	assert.Equal(t, []slog.Attr{slog.String("table", "users"), slog.Int("user_id", 42)}, ErrorAttrs(err))
+
+	joined := errors.Join(Errorw(os.ErrClosed, "a", 1), Errorw(os.ErrExist, "b", 2))
+	assert.Equal(t, []slog.Attr{slog.Int("a", 1), slog.Int("b", 2)}, ErrorAttrs(joined))
+	assert.Equal(t, 0, len(ErrorAttrs(os.ErrClosed)))
+	assert.Equal(t, 0, len(ErrorAttrs(nil)))
+}
+
+func TestWrapErrLogged(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(&Config{out: buf})
+	err := loadUser(42)
+
+	l.logArgs(slog.LevelError, "request failed:", err)
+	assert.Contains(t, buf.String(), `msg="request failed: loading profile: query: file does not exist" table=users user_id=42`)
+
+	buf.Reset()
+	l.logf(slog.LevelError, "request failed: %v", err)
+	assert.Contains(t, buf.String(), `msg="request failed: loading profile: query: file does not exist" table=users user_id=42`)
+
+	buf.Reset()
+	useLogger(t, l)
+	LogIfError(err, Error, "request failed")
+	assert.Contains(t, buf.String(), `msg="request failed" error.message="loading profile: query: file does not exist"`)
+	assert.Contains(t, buf.String(), `table=users user_id=42`)
+	// The layer added by WrapErr only adds its message, the one added by Errorw doesn't show
+	// up in the error chain.
+	assert.Contains(t, buf.String(), `error.cause.message="query: file does not exist" error.cause.cause.message="file does not exist" error.cause.cause.type=*errors.errorString`)
+	assert.NotContains(t, buf.String(), "eslog.wrapError")
+}
+
+func TestWrapErrAttrsLoggedOnce(t *testing.T) {
+	err := WrapErr(errors.New("boom"), "ctx", "user", 42)
+	for _, format := range []Format{TextFormat, JSONFormat} {
+		t.Run(format.String(), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := New(&Config{Format: format, out: buf})
+			useLogger(t, l)
+
+			LogIfError(err, Error)
+			LogIfError(err, Error, "failed")
+			l.logArgs(slog.LevelError, "failed", err, Err(err))
+			l.CheckErr(err, "failed")
+
+			key := "user=42"
+			if format == JSONFormat {
+				key = `"user":42`
+			}
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.Equal(t, 4, len(lines))
+			for _, line := range lines {
+				assert.Equal(t, 1, strings.Count(line, key))
+			}
+		})
+	}
+}
+
+// detailErr is comparable by its static type but holds an uncomparable value.
+type detailErr struct {
+	details any
+}
+
+func (e detailErr) Error() string {
+	return "invalid request"
+}
+
+func TestErrorArgAttrsUncomparableError(t *testing.T) {
+	err := detailErr{details: []string{"name is empty"}}
+	assert.Equal(t, 0, len(errorArgAttrs([]any{err, Err(err), err})))
+
+	wrapped := Errorw(err, "user", 42)
+	assert.Equal(t, []any{slog.Int("user", 42)}, errorArgAttrs([]any{wrapped, Err(wrapped)}))
+}
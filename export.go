@@ -0,0 +1,354 @@
+package eslog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of BatchOptions.
+const (
+	defaultBatchMaxRecords = 512
+	defaultBatchMaxBytes   = 1 << 20
+	defaultBatchInterval   = time.Second
+	defaultBatchMaxRetries = 5
+	defaultMinBackoff      = 100 * time.Millisecond
+	defaultMaxBackoff      = 10 * time.Second
+	// batchQueueSize is the number of full batches which can wait to be sent before
+	// logging blocks.
+	batchQueueSize = 8
+)
+
+// BatchOptions configures how exporters like OTLPHandler and LokiHandler batch records and
+// retry failed requests. Batches are sent in a background goroutine.
+type BatchOptions struct {
+	// MaxRecords is the maximum number of records of a batch. Defaults to 512.
+	MaxRecords int
+	// MaxBytes is the maximum size of the encoded records of a batch. Defaults to 1 MiB.
+	MaxBytes int
+	// Interval is the maximum time a record waits for its batch to be sent. Defaults to
+	// one second.
+	Interval time.Duration
+	// MaxRetries is the number of retries of a failed batch. Defaults to 5, a negative
+	// value disables retries. Only network errors, 429 and 5xx responses are retried.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry. It is doubled for each retry up to
+	// MaxBackoff. Defaults to 100ms and 10s. A Retry-After header of the response is
+	// respected.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnError is called if a batch sent in the background can't be delivered. Defaults to
+	// writing the error to os.Stderr.
+	OnError func(err error)
+	// Spool stores batches which can't be delivered after all retries. They are replayed
+	// in order before newer batches once the destination recovers. The spool is closed by
+	// the handler.
+	Spool *Spool
+}
+
+// retryableError is returned by send functions of a batcher if the request can be retried.
+type retryableError struct {
+	err error
+	// after is the delay requested by the server, e.g. by a Retry-After header.
+	after time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// queuedBatch is a batch waiting to be sent. If done is set the result is sent to done
+// instead of OnError.
+type queuedBatch[T any] struct {
+	items []T
+	done  chan error
+}
+
+// batcher collects items into batches and sends them in a background goroutine. encode
+// creates the request body of a batch, post sends it.
+type batcher[T any] struct {
+	opts   BatchOptions
+	encode func(items []T) []byte
+	post   func(body []byte) error
+
+	mu     sync.Mutex
+	items  []T
+	size   int
+	closed bool
+
+	queue chan queuedBatch[T]
+	stop  chan struct{}
+	done  sync.WaitGroup
+}
+
+func newBatcher[T any](opts BatchOptions, encode func(items []T) []byte, post func(body []byte) error) *batcher[T] {
+	if opts.MaxRecords <= 0 {
+		opts.MaxRecords = defaultBatchMaxRecords
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultBatchMaxBytes
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultBatchInterval
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultBatchMaxRetries
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) {
+			_, _ = fmt.Fprintf(os.Stderr, "eslog: export failed: %s\n", err)
+		}
+	}
+
+	b := &batcher[T]{
+		opts:   opts,
+		encode: encode,
+		post:   post,
+		queue:  make(chan queuedBatch[T], batchQueueSize),
+		stop:   make(chan struct{}),
+	}
+	b.done.Add(2)
+	go b.work()
+	go b.tick()
+	return b
+}
+
+// add adds item of the given size to the current batch. The batch is queued to be sent if
+// it is full.
+func (b *batcher[T]) add(item T, size int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrHandlerClosed
+	}
+	if len(b.items) > 0 && b.size+size > b.opts.MaxBytes {
+		b.queue <- queuedBatch[T]{items: b.take()}
+	}
+	b.items = append(b.items, item)
+	b.size += size
+	if len(b.items) >= b.opts.MaxRecords || b.size >= b.opts.MaxBytes {
+		b.queue <- queuedBatch[T]{items: b.take()}
+	}
+	return nil
+}
+
+// take returns the items of the current batch and starts a new one. b.mu must be held.
+func (b *batcher[T]) take() []T {
+	items := b.items
+	b.items, b.size = nil, 0
+	return items
+}
+
+// flush sends the current batch and waits until all batches queued before are sent. It
+// returns the error of the current batch.
+func (b *batcher[T]) flush() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	done := make(chan error, 1)
+	b.queue <- queuedBatch[T]{items: b.take(), done: done}
+	b.mu.Unlock()
+	return <-done
+}
+
+// close sends the current batch, stops the background goroutines and closes the spool.
+func (b *batcher[T]) close() error {
+	err := b.flush()
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	if len(b.items) > 0 {
+		b.queue <- queuedBatch[T]{items: b.take()}
+	}
+	close(b.queue)
+	close(b.stop)
+	b.mu.Unlock()
+
+	b.done.Wait()
+	if b.opts.Spool != nil {
+		err = errors.Join(err, b.opts.Spool.Close())
+	}
+	return err
+}
+
+// work sends the queued batches until the queue is closed.
+func (b *batcher[T]) work() {
+	defer b.done.Done()
+	for qb := range b.queue {
+		b.replay()
+		var err error
+		if len(qb.items) > 0 {
+			err = b.deliver(b.encode(qb.items))
+		}
+		if qb.done != nil {
+			qb.done <- err
+		} else if err != nil {
+			b.opts.OnError(err)
+		}
+	}
+}
+
+// tick queues the current batch once per interval. While the spool is not empty an empty
+// batch is queued once per retry interval to replay the spool.
+func (b *batcher[T]) tick() {
+	defer b.done.Done()
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+	var retry <-chan time.Time
+	if b.opts.Spool != nil {
+		retryTicker := time.NewTicker(b.opts.Spool.opts.RetryInterval)
+		defer retryTicker.Stop()
+		retry = retryTicker.C
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			if len(b.items) > 0 && !b.closed {
+				b.queue <- queuedBatch[T]{items: b.take()}
+			}
+			b.mu.Unlock()
+		case <-retry:
+			b.mu.Lock()
+			if !b.closed && !b.opts.Spool.empty() {
+				b.queue <- queuedBatch[T]{}
+			}
+			b.mu.Unlock()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// deliver sends body. If the destination is down body is spooled.
+func (b *batcher[T]) deliver(body []byte) error {
+	spool := b.opts.Spool
+	if spool != nil && !spool.empty() {
+		// The replay failed, so the destination is still down.
+		return spool.append(body)
+	}
+	err := b.sendWithRetry(body)
+	var re *retryableError
+	if err != nil && spool != nil && errors.As(err, &re) {
+		return spool.append(body)
+	}
+	return err
+}
+
+// replay sends the spooled batches in order until the spool is empty or the destination
+// fails. Batches rejected by the destination are dropped.
+func (b *batcher[T]) replay() {
+	spool := b.opts.Spool
+	if spool == nil {
+		return
+	}
+	for {
+		body, err := spool.peek()
+		if err != nil {
+			b.opts.OnError(err)
+			return
+		}
+		if body == nil {
+			return
+		}
+		if err := b.post(body); err != nil {
+			var re *retryableError
+			if errors.As(err, &re) {
+				return
+			}
+			b.opts.OnError(err)
+		}
+		spool.ack()
+	}
+}
+
+// sendWithRetry posts body and retries retryable errors with exponential backoff.
+func (b *batcher[T]) sendWithRetry(body []byte) error {
+	backoff := b.opts.MinBackoff
+	for attempt := 0; ; attempt++ {
+		err := b.post(body)
+		var re *retryableError
+		if err == nil || !errors.As(err, &re) || attempt >= b.opts.MaxRetries {
+			return err
+		}
+
+		// Equal jitter (between half and all of the backoff) keeps many clients from retrying
+		// at the same time.
+		delay := backoff/2 + rand.N(backoff/2+1)
+		delay = max(delay, re.after)
+		time.Sleep(delay)
+		backoff = min(2*backoff, b.opts.MaxBackoff)
+	}
+}
+
+// postHTTP posts body to url. Network errors, 429 and 5xx responses are returned as
+// retryableError.
+func postHTTP(client *http.Client, url, contentType string, headers map[string]string, body []byte, compress bool) error {
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &retryableError{err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("eslog: POST %s: %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		after, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &retryableError{err: err, after: time.Duration(after) * time.Second}
+	}
+	return err
+}
@@ -0,0 +1,183 @@
+package eslog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// joinBatch encodes a batch of strings.
+func joinBatch(items []string) []byte {
+	return []byte(strings.Join(items, ","))
+}
+
+// batchRecorder records the batches posted by a batcher. While down is set posting fails.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	down    atomic.Bool
+}
+
+func (r *batchRecorder) post(body []byte) error {
+	if r.down.Load() {
+		return &retryableError{err: errors.New("unavailable")}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, strings.Split(string(body), ","))
+	return nil
+}
+
+func (r *batchRecorder) Batches() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]string{}, r.batches...)
+}
+
+func TestBatcherLimits(t *testing.T) {
+	rec := &batchRecorder{}
+	b := newBatcher(BatchOptions{MaxRecords: 3, MaxBytes: 10, Interval: time.Hour}, joinBatch, rec.post)
+
+	for _, item := range []string{"a", "b", "c", "d", "eeeeeeee", "ffff", "g"} {
+		assert.NoError(t, b.add(item, len(item)))
+	}
+	assert.NoError(t, b.flush())
+
+	// "ffff" starts a new batch, because it would exceed MaxBytes.
+	expected := [][]string{{"a", "b", "c"}, {"d", "eeeeeeee"}, {"ffff", "g"}}
+	assert.Equal(t, expected, rec.Batches())
+
+	assert.NoError(t, b.close())
+	assert.Equal(t, ErrHandlerClosed, b.add("h", 1))
+}
+
+func TestBatcherInterval(t *testing.T) {
+	rec := &batchRecorder{}
+	b := newBatcher(BatchOptions{Interval: 10 * time.Millisecond}, joinBatch, rec.post)
+	defer func() { _ = b.close() }()
+
+	assert.NoError(t, b.add("a", 1))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.Batches()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("batch was not sent after the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, [][]string{{"a"}}, rec.Batches())
+}
+
+func TestBatcherRetries(t *testing.T) {
+	var attempts atomic.Int32
+	post := func([]byte) error {
+		if attempts.Add(1) < 3 {
+			return &retryableError{err: errors.New("unavailable")}
+		}
+		return nil
+	}
+	b := newBatcher(BatchOptions{MinBackoff: time.Millisecond}, joinBatch, post)
+
+	assert.NoError(t, b.add("a", 1))
+	assert.NoError(t, b.close())
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestBatcherGivesUp(t *testing.T) {
+	errPermanent := errors.New("bad request")
+	var attempts atomic.Int32
+	var reported []error
+	tests := []struct {
+		name     string
+		err      error
+		attempts int32
+	}{
+		{"not retryable", errPermanent, 1},
+		{"retries exhausted", &retryableError{err: errPermanent}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts.Store(0)
+			reported = nil
+			post := func([]byte) error {
+				attempts.Add(1)
+				return tt.err
+			}
+			onError := func(err error) { reported = append(reported, err) }
+			b := newBatcher(BatchOptions{MaxRecords: 1, MaxRetries: 2, MinBackoff: time.Millisecond, OnError: onError}, joinBatch, post)
+
+			// The full batch is sent in the background and its error is reported.
+			assert.NoError(t, b.add("a", 1))
+			assert.NoError(t, b.close())
+			assert.Equal(t, tt.attempts, attempts.Load())
+			assert.Equal(t, 1, len(reported))
+			assert.Equal(t, true, errors.Is(reported[0], errPermanent))
+		})
+	}
+}
+
+func TestBatcherSpool(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir(), SpoolOptions{RetryInterval: time.Hour})
+	assert.NoError(t, err)
+	rec := &batchRecorder{}
+	rec.down.Store(true)
+	b := newBatcher(BatchOptions{MaxRetries: 1, MinBackoff: time.Millisecond, Spool: spool}, joinBatch, rec.post)
+
+	// While the destination is down the batches are spooled.
+	for _, item := range []string{"a", "b", "c"} {
+		assert.NoError(t, b.add(item, 1))
+		assert.NoError(t, b.flush())
+	}
+	assert.Equal(t, 0, len(rec.Batches()))
+	assert.Equal(t, false, spool.empty())
+
+	// Once it recovers the spooled batches are replayed before the new one.
+	rec.down.Store(false)
+	assert.NoError(t, b.add("d", 1))
+	assert.NoError(t, b.close())
+	assert.Equal(t, [][]string{{"a"}, {"b"}, {"c"}, {"d"}}, rec.Batches())
+	assert.Equal(t, true, spool.empty())
+}
+
+func TestPostHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		retryable  bool
+		after      time.Duration
+	}{
+		{"ok", http.StatusNoContent, "", false, 0},
+		{"bad request", http.StatusBadRequest, "", false, 0},
+		{"too many requests", http.StatusTooManyRequests, "2", true, 2 * time.Second},
+		{"server error", http.StatusBadGateway, "", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "secret", r.Header.Get("Authorization"))
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := postHTTP(srv.Client(), srv.URL, "text/plain", map[string]string{"Authorization": "secret"}, []byte("body"), false)
+			var re *retryableError
+			assert.Equal(t, tt.retryable, errors.As(err, &re))
+			if tt.status < 300 {
+				assert.NoError(t, err)
+			}
+			if re != nil {
+				assert.Equal(t, tt.after, re.after)
+			}
+		})
+	}
+}
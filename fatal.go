@@ -2,22 +2,30 @@ package eslog
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"os"
-	"strings"
 )
 
 const LevelFatal = slog.Level(12)
 
-// levelNames maps the LevelFatal to string "FATAL"
+// levelNames maps the custom levels LevelFatal and LevelTrace to their string representation.
 var levelNames = map[slog.Leveler]string{
 	LevelFatal: "FATAL",
+	LevelTrace: "TRACE",
+}
+
+// levelName returns the name of level. The custom levels are named by levelNames.
+func levelName(level slog.Level) string {
+	if name, exists := levelNames[level]; exists {
+		return name
+	}
+	return level.String()
 }
 
 // Fatal logs at [LevelFatal].  Multiple args are joined with " ".
 func Fatal(args ...any) {
-	Logger.Fatal(strings.Join(convertAnyToString(args...), " "))
+	Logger.logArgs(LevelFatal, args...)
+	Logger.exit()
 }
 
 // Fatalf logs at [LevelFatal]. The function uses fmt.Sprintf with given format and args
@@ -28,22 +36,32 @@ func Fatalf(format string, args ...any) {
 
 // Fatal logs at [LevelFatal]. Also it calls os.Exit(1).
 func (l eSlogLogger) Fatal(msg string, args ...any) {
-	l.Log(context.Background(), LevelFatal, msg, args...)
-	os.Exit(1)
+	l.log(context.Background(), LevelFatal, msg, append(args[:len(args):len(args)], errorArgAttrs(args)...)...)
+	l.exit()
 }
 
 // Fatalf logs at [LevelFatal]. The function uses fmt.Sprintf with given format and args
 // and log it. Also it calls os.Exit(1).
 func (l eSlogLogger) Fatalf(format string, args ...any) {
-	l.Fatal(fmt.Sprintf(format, args...))
+	l.logf(LevelFatal, format, args...)
+	l.exit()
 }
 
-// FatalLn logs at [LevelFatal] and appends a newline, then exits.
-func FatalLn(msg string, args ...any) {
-	Logger.FatalLn(msg, args...)
+// FatalLn logs at [LevelFatal]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline. Also it calls os.Exit(1).
+func FatalLn(args ...any) {
+	Logger.FatalLn(args...)
 }
 
-func (l eSlogLogger) FatalLn(msg string, args ...any) {
-	args = append(args, "\n")
-	Logger.Fatal(msg, args...)
+// FatalLn logs at [LevelFatal]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline. Also it calls os.Exit(1).
+func (l eSlogLogger) FatalLn(args ...any) {
+	l.logln(LevelFatal, args...)
+	l.exit()
+}
+
+// exit closes the handlers of the Logger to write buffered records and calls os.Exit(1).
+func (l eSlogLogger) exit() {
+	_ = l.Close()
+	os.Exit(1)
 }
@@ -0,0 +1,140 @@
+package eslog
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// field is a resolved attribute of a record together with the groups it belongs to. It is
+// used by handlers which render records themselves instead of using slog's text or JSON
+// handler.
+type field struct {
+	groups []string
+	key    string
+	value  slog.Value
+}
+
+// name joins the groups and the key of f with sep.
+func (f field) name(sep string) string {
+	if len(f.groups) == 0 {
+		return f.key
+	}
+	return strings.Join(f.groups, sep) + sep + f.key
+}
+
+// attrState keeps track of the attributes and groups added by WithAttrs and WithGroup.
+// It is immutable, so it can be shared by derived handlers.
+type attrState struct {
+	groups []string
+	fields []field
+}
+
+func (s attrState) withAttrs(attrs []slog.Attr) attrState {
+	fields := append([]field{}, s.fields...)
+	for _, a := range attrs {
+		fields = appendField(fields, s.groups, a)
+	}
+	return attrState{groups: s.groups, fields: fields}
+}
+
+func (s attrState) withGroup(name string) attrState {
+	if name == "" {
+		return s
+	}
+	groups := append(append([]string{}, s.groups...), name)
+	return attrState{groups: groups, fields: s.fields}
+}
+
+// recordFields returns the fields added by WithAttrs followed by the attributes of r.
+func (s attrState) recordFields(r slog.Record) []field {
+	fields := make([]field, 0, len(s.fields)+r.NumAttrs())
+	fields = append(fields, s.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendField(fields, s.groups, a)
+		return true
+	})
+	return fields
+}
+
+// appendField resolves a and appends it to fields. Groups are flattened, empty attributes
+// and empty groups are skipped like slog's handlers do.
+func appendField(fields []field, groups []string, a slog.Attr) []field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return append(fields, field{groups: groups, key: a.Key, value: a.Value})
+	}
+	if a.Key != "" {
+		groups = append(append([]string{}, groups...), a.Key)
+	}
+	for _, ga := range a.Value.Group() {
+		fields = appendField(fields, groups, ga)
+	}
+	return fields
+}
+
+// valueString returns the string representation of v used by text based formats.
+func valueString(v slog.Value) string {
+	if v.Kind() == slog.KindTime {
+		return v.Time().Format(time.RFC3339Nano)
+	}
+	return v.String()
+}
+
+// appendLogfmt appends fields as key=value pairs separated by spaces to b. Keys of grouped
+// fields are joined with ".", values are quoted if needed.
+func appendLogfmt(b []byte, fields []field) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = append(b, f.name(".")...)
+		b = append(b, '=')
+		s := valueString(f.value)
+		if needsQuoting(s) {
+			b = strconv.AppendQuote(b, s)
+		} else {
+			b = append(b, s...)
+		}
+	}
+	return b
+}
+
+// needsQuoting reports whether s has to be quoted in logfmt.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestFields turns fields back into attributes. Consecutive fields of the same group are
+// put into one group attribute.
+func nestFields(fields []field, depth int) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i := 0; i < len(fields); {
+		f := fields[i]
+		if len(f.groups) == depth {
+			attrs = append(attrs, slog.Attr{Key: f.key, Value: f.value})
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(fields) && len(fields[j].groups) > depth && fields[j].groups[depth] == f.groups[depth] {
+			j++
+		}
+		attrs = append(attrs, slog.Attr{Key: f.groups[depth], Value: slog.GroupValue(nestFields(fields[i:j], depth+1)...)})
+		i = j
+	}
+	return attrs
+}
@@ -0,0 +1,169 @@
+package eslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// defaultFingersCrossedBufferSize is used if FingersCrossedOptions.BufferSize is not set.
+const defaultFingersCrossedBufferSize = 100
+
+// ReplayedKey is the attribute which marks records replayed by Config.FingersCrossed.
+const ReplayedKey = "replayed"
+
+// FingersCrossedOptions configures the buffering of records below the level of the Logger
+// with Config.FingersCrossed.
+type FingersCrossedOptions struct {
+	// BufferSize is the number of records kept. Older records are dropped. Defaults to 100.
+	BufferSize int
+	// Threshold is the level which triggers the replay of the buffered records. Defaults to
+	// slog.LevelError.
+	Threshold slog.Leveler
+	// BufferLevel is the minimum level of buffered records. Defaults to slog.LevelDebug.
+	BufferLevel slog.Leveler
+}
+
+// fingersCrossedBufferLevel returns the BufferLevel of opts or its default.
+func fingersCrossedBufferLevel(opts FingersCrossedOptions) slog.Leveler {
+	if opts.BufferLevel == nil {
+		return slog.LevelDebug
+	}
+	return opts.BufferLevel
+}
+
+// minLeveler is the lower of two levels.
+type minLeveler struct {
+	a, b slog.Leveler
+}
+
+func (m minLeveler) Level() slog.Level {
+	return min(m.a.Level(), m.b.Level())
+}
+
+// fingersCrossedEntry is a buffered record together with the handler which has to handle
+// it.
+type fingersCrossedEntry struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// fingersCrossedBuffer is a ring buffer of the most recent records.
+type fingersCrossedBuffer struct {
+	mu      sync.Mutex
+	entries []fingersCrossedEntry
+	// next is the index of the oldest entry once the buffer is full.
+	next int
+}
+
+func (b *fingersCrossedBuffer) add(e fingersCrossedEntry, size int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) < size {
+		b.entries = append(b.entries, e)
+		return
+	}
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % size
+}
+
+// take returns the buffered entries in order and empties the buffer.
+func (b *fingersCrossedBuffer) take() []fingersCrossedEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := append(b.entries[b.next:], b.entries[:b.next]...)
+	b.entries, b.next = nil, 0
+	return entries
+}
+
+// fingersCrossedKey is the context key of the buffer of a request.
+type fingersCrossedKey struct{}
+
+// WithFingersCrossed returns a context with its own buffer for Config.FingersCrossed.
+// Records logged with the context are buffered separately, so an error of a request only
+// replays the records of that request.
+func WithFingersCrossed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fingersCrossedKey{}, &fingersCrossedBuffer{})
+}
+
+// fingersCrossedHandler buffers records below level instead of dropping them. If a record
+// at or above the threshold is logged the buffered records are passed to the wrapped
+// handler first, marked with the attribute "replayed".
+type fingersCrossedHandler struct {
+	h     slog.Handler
+	level slog.Leveler
+	opts  *FingersCrossedOptions
+	// buffer is used for records logged without a context of WithFingersCrossed.
+	buffer *fingersCrossedBuffer
+}
+
+// newFingersCrossedHandler wraps h, which has to handle records down to
+// opts.BufferLevel. level is the level of records which are passed through immediately.
+func newFingersCrossedHandler(h slog.Handler, level slog.Leveler, opts FingersCrossedOptions) *fingersCrossedHandler {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultFingersCrossedBufferSize
+	}
+	if opts.Threshold == nil {
+		opts.Threshold = slog.LevelError
+	}
+	opts.BufferLevel = fingersCrossedBufferLevel(opts)
+	return &fingersCrossedHandler{h: h, level: level, opts: &opts, buffer: &fingersCrossedBuffer{}}
+}
+
+func (f *fingersCrossedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level != LevelPrint && level < min(f.level.Level(), f.opts.BufferLevel.Level()) {
+		return false
+	}
+	return f.h.Enabled(ctx, level)
+}
+
+func (f *fingersCrossedHandler) Handle(ctx context.Context, r slog.Record) error {
+	switch {
+	case r.Level == LevelPrint:
+		return f.h.Handle(ctx, r)
+	case r.Level >= f.opts.Threshold.Level():
+		return errors.Join(f.replay(ctx), f.h.Handle(ctx, r))
+	case r.Level >= f.level.Level():
+		return f.h.Handle(ctx, r)
+	default:
+		f.bufferFor(ctx).add(fingersCrossedEntry{ctx: ctx, h: f.h, r: r.Clone()}, f.opts.BufferSize)
+		return nil
+	}
+}
+
+func (f *fingersCrossedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &fingersCrossedHandler{h: f.h.WithAttrs(attrs), level: f.level, opts: f.opts, buffer: f.buffer}
+}
+
+func (f *fingersCrossedHandler) WithGroup(name string) slog.Handler {
+	return &fingersCrossedHandler{h: f.h.WithGroup(name), level: f.level, opts: f.opts, buffer: f.buffer}
+}
+
+func (f *fingersCrossedHandler) Flush() error {
+	return flushHandler(f.h)
+}
+
+func (f *fingersCrossedHandler) Close() error {
+	return closeHandler(f.h)
+}
+
+// bufferFor returns the buffer of the request of ctx or the global buffer.
+func (f *fingersCrossedHandler) bufferFor(ctx context.Context) *fingersCrossedBuffer {
+	if b, ok := ctx.Value(fingersCrossedKey{}).(*fingersCrossedBuffer); ok {
+		return b
+	}
+	return f.buffer
+}
+
+// replay passes the buffered records of ctx to their handlers.
+func (f *fingersCrossedHandler) replay(ctx context.Context) error {
+	var errs []error
+	for _, e := range f.bufferFor(ctx).take() {
+		r := e.r.Clone()
+		r.AddAttrs(slog.Bool(ReplayedKey, true))
+		errs = append(errs, e.h.Handle(e.ctx, r))
+	}
+	return errors.Join(errs...)
+}
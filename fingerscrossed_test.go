@@ -0,0 +1,92 @@
+package eslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestFingersCrossedHandler(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newFingersCrossedHandler(rec, slog.LevelInfo, FingersCrossedOptions{BufferSize: 2})
+
+	for _, msg := range []string{"debug 1", "debug 2", "debug 3"} {
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelDebug, msg, 0)))
+	}
+	handleMessage(t, h, "info")
+	assert.Equal(t, []string{"info"}, rec.Messages())
+
+	// The error replays the last two debug records.
+	assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "error", 0)))
+	assert.Equal(t, []string{"info", "debug 2", "debug 3", "error"}, rec.Messages())
+	assert.Equal(t, []slog.Attr{slog.Bool(ReplayedKey, true), slog.Bool(ReplayedKey, true)}, rec.attrs)
+
+	// The buffer is empty after the replay.
+	assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), LevelFatal, "fatal", 0)))
+	assert.Equal(t, []string{"info", "debug 2", "debug 3", "error", "fatal"}, rec.Messages())
+}
+
+func TestFingersCrossedHandlerEnabled(t *testing.T) {
+	level := &slog.LevelVar{}
+	h := newFingersCrossedHandler(&recordingHandler{}, level, FingersCrossedOptions{})
+
+	assert.Equal(t, false, h.Enabled(context.Background(), LevelTrace))
+	assert.Equal(t, true, h.Enabled(context.Background(), slog.LevelDebug))
+	level.Set(LevelTrace)
+	assert.Equal(t, true, h.Enabled(context.Background(), LevelTrace))
+}
+
+func TestFingersCrossedContext(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newFingersCrossedHandler(rec, slog.LevelInfo, FingersCrossedOptions{})
+
+	failing := WithFingersCrossed(context.Background())
+	succeeding := WithFingersCrossed(context.Background())
+	assert.NoError(t, h.Handle(failing, slog.NewRecord(time.Now(), slog.LevelDebug, "failing request", 0)))
+	assert.NoError(t, h.Handle(succeeding, slog.NewRecord(time.Now(), slog.LevelDebug, "succeeding request", 0)))
+	assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelDebug, "background", 0)))
+
+	assert.NoError(t, h.Handle(failing, slog.NewRecord(time.Now(), slog.LevelError, "request failed", 0)))
+	assert.Equal(t, []string{"failing request", "request failed"}, rec.Messages())
+}
+
+func TestFingersCrossedConfig(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelInfo)
+	defer logLevel.Set(slog.LevelDebug)
+	l := New(&Config{FingersCrossed: &FingersCrossedOptions{}, out: buf})
+
+	l.Debug("connecting", "attempt", 1)
+	l.With("db", "users").Debug("connected")
+	l.Info("started")
+	assert.NotContains(t, buf.String(), "connecting")
+
+	l.Error("query failed")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.Contains(t, lines[0], "msg=started")
+	assert.Contains(t, lines[1], "level=DEBUG msg=connecting attempt=1 replayed=true")
+	assert.Contains(t, lines[2], "level=DEBUG msg=connected db=users replayed=true")
+	assert.Contains(t, lines[3], "level=ERROR msg=\"query failed\"")
+}
+
+func TestFingersCrossedPrintV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	for _, cfg := range []*Config{
+		{FingersCrossed: &FingersCrossedOptions{}, out: buf},
+		{Sinks: []Sink{{Writer: buf, Level: slog.LevelDebug}}},
+	} {
+		buf.Reset()
+		l := New(cfg)
+		l.SetVerbosity(0)
+		l.PrintV(2, "debug output\n")
+		l.PrintV(0, "normal output\n")
+		l.SetVerbosity(2)
+		assert.Equal(t, "normal output\n", buf.String())
+	}
+}
@@ -0,0 +1,242 @@
+package eslog
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// defaultFlightRecorderSize is used if FlightRecorderOptions.Size is not set.
+const defaultFlightRecorderSize = 4 << 20
+
+// The flight recorder file starts with a header followed by the ring of entries:
+//
+//	magic [8]byte | size uint64 | head uint64 | tail uint64 | reserved
+//
+// size is the size of the ring, head and tail are the positions after the newest and of
+// the oldest entry. They only grow, the offset in the ring is the position modulo size.
+// Entries have the same layout as in the spool: [length uint32][CRC-32][payload]. They
+// wrap around at the end of the ring.
+const (
+	flightRecorderMagic      = "ESLOGFR1"
+	flightRecorderHeaderSize = 64
+	flightRecorderHeadOff    = 16
+	flightRecorderTailOff    = 24
+)
+
+// ErrFlightRecorderFormat is returned by ReadFlightRecorder if the file is not a flight
+// recorder file.
+var ErrFlightRecorderFormat = errors.New("eslog: not a flight recorder file")
+
+// FlightRecorderOptions configures a FlightRecorder.
+type FlightRecorderOptions struct {
+	// Size is the size of the ring of records in bytes. If the ring is full the oldest
+	// records are overwritten. Defaults to 4 MiB.
+	Size int64
+	// Level is the minimum level of recorded records. It is independent of the level of the
+	// Logger. Defaults to slog.LevelDebug.
+	Level slog.Leveler
+}
+
+// FlightRecorder writes records to a memory-mapped ring file. Writing a record is a copy
+// into memory, the kernel writes the pages to the file. So the last records survive if the
+// process is killed, e.g. by the OOM killer, but not if the machine crashes. Read them
+// with ReadFlightRecorder or the eslog-flightrecorder command.
+//
+// A file is only written by one process at a time. If it already contains records of a
+// previous run new records are appended.
+type FlightRecorder struct {
+	attrs attrState
+	level slog.Leveler
+	ring  *flightRecorderRing
+}
+
+// flightRecorderRing is shared between a FlightRecorder and the handlers derived from it.
+type flightRecorderRing struct {
+	mu     sync.Mutex
+	data   []byte
+	size   uint64
+	unmap  func() error
+	closed bool
+}
+
+// OpenFlightRecorder opens or creates the flight recorder file at path. A file of a
+// different size is reset.
+func OpenFlightRecorder(path string, opts FlightRecorderOptions) (*FlightRecorder, error) {
+	if opts.Size <= 0 {
+		opts.Size = defaultFlightRecorderSize
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelDebug
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	// The mapping stays valid after the file is closed.
+	defer f.Close()
+	if err := f.Truncate(flightRecorderHeaderSize + opts.Size); err != nil {
+		return nil, err
+	}
+	data, unmap, err := mapFile(f, int(flightRecorderHeaderSize+opts.Size))
+	if err != nil {
+		return nil, fmt.Errorf("eslog: mapping flight recorder %s: %w", path, err)
+	}
+
+	ring := &flightRecorderRing{data: data, size: uint64(opts.Size), unmap: unmap}
+	if _, _, ok := parseFlightRecorderHeader(data); !ok {
+		ring.reset()
+	}
+	return &FlightRecorder{level: opts.Level, ring: ring}, nil
+}
+
+func (fr *FlightRecorder) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= fr.level.Level()
+}
+
+func (fr *FlightRecorder) Handle(_ context.Context, r slog.Record) error {
+	return fr.ring.write(encodeSpoolRecord(r, fr.attrs.recordFields(r)))
+}
+
+func (fr *FlightRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &FlightRecorder{attrs: fr.attrs.withAttrs(attrs), level: fr.level, ring: fr.ring}
+}
+
+func (fr *FlightRecorder) WithGroup(name string) slog.Handler {
+	return &FlightRecorder{attrs: fr.attrs.withGroup(name), level: fr.level, ring: fr.ring}
+}
+
+// Close unmaps the file. The records stay in the file.
+func (fr *FlightRecorder) Close() error {
+	ring := fr.ring
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if ring.closed {
+		return nil
+	}
+	ring.closed = true
+	err := ring.unmap()
+	ring.data = nil
+	return err
+}
+
+// reset writes an empty header. ring.mu must be held or ring must not be shared yet.
+func (ring *flightRecorderRing) reset() {
+	copy(ring.data, flightRecorderMagic)
+	binary.LittleEndian.PutUint64(ring.data[8:], ring.size)
+	binary.LittleEndian.PutUint64(ring.data[flightRecorderHeadOff:], 0)
+	binary.LittleEndian.PutUint64(ring.data[flightRecorderTailOff:], 0)
+}
+
+// write appends an entry with payload. The oldest entries are dropped to make room. The
+// tail is moved before and the head after the entry is written, so the entries between
+// tail and head are complete whenever the process dies.
+func (ring *flightRecorderRing) write(payload []byte) error {
+	n := uint64(spoolHeaderSize + len(payload))
+	if n > ring.size {
+		return fmt.Errorf("eslog: record of %d bytes exceeds the flight recorder size", len(payload))
+	}
+
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if ring.closed {
+		return ErrHandlerClosed
+	}
+
+	head := binary.LittleEndian.Uint64(ring.data[flightRecorderHeadOff:])
+	tail := binary.LittleEndian.Uint64(ring.data[flightRecorderTailOff:])
+	for head+n-tail > ring.size {
+		var hdr [spoolHeaderSize]byte
+		ring.read(tail, hdr[:])
+		tail += spoolHeaderSize + uint64(binary.LittleEndian.Uint32(hdr[:]))
+		if tail > head {
+			tail = head
+		}
+	}
+	binary.LittleEndian.PutUint64(ring.data[flightRecorderTailOff:], tail)
+
+	var hdr [spoolHeaderSize]byte
+	binary.LittleEndian.PutUint32(hdr[:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(hdr[4:], crc32.ChecksumIEEE(payload))
+	ring.copyIn(head, hdr[:])
+	ring.copyIn(head+spoolHeaderSize, payload)
+	binary.LittleEndian.PutUint64(ring.data[flightRecorderHeadOff:], head+n)
+	return nil
+}
+
+// copyIn copies b to the ring at pos.
+func (ring *flightRecorderRing) copyIn(pos uint64, b []byte) {
+	data := ring.data[flightRecorderHeaderSize:]
+	off := pos % ring.size
+	n := copy(data[off:], b)
+	copy(data, b[n:])
+}
+
+// read fills b from the ring at pos.
+func (ring *flightRecorderRing) read(pos uint64, b []byte) {
+	readRing(ring.data[flightRecorderHeaderSize:], pos, b)
+}
+
+// readRing fills b from the ring data at pos.
+func readRing(data []byte, pos uint64, b []byte) {
+	off := pos % uint64(len(data))
+	n := copy(b, data[off:])
+	copy(b[n:], data)
+}
+
+// parseFlightRecorderHeader returns the head and tail of the flight recorder file data.
+// ok is false if data has no valid header.
+func parseFlightRecorderHeader(data []byte) (head, tail uint64, ok bool) {
+	if len(data) < flightRecorderHeaderSize || string(data[:8]) != flightRecorderMagic {
+		return 0, 0, false
+	}
+	size := binary.LittleEndian.Uint64(data[8:])
+	head = binary.LittleEndian.Uint64(data[flightRecorderHeadOff:])
+	tail = binary.LittleEndian.Uint64(data[flightRecorderTailOff:])
+	if size != uint64(len(data)-flightRecorderHeaderSize) || tail > head || head-tail > size {
+		return 0, 0, false
+	}
+	return head, tail, true
+}
+
+// ReadFlightRecorder returns the records of the flight recorder file at path, oldest
+// first. Reading stops at the first corrupted entry, e.g. one which was overwritten while
+// the file was read.
+func ReadFlightRecorder(path string) ([]slog.Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	head, tail, ok := parseFlightRecorderHeader(data)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFlightRecorderFormat, path)
+	}
+
+	ring := data[flightRecorderHeaderSize:]
+	var records []slog.Record
+	for pos := tail; pos+spoolHeaderSize <= head; {
+		var hdr [spoolHeaderSize]byte
+		readRing(ring, pos, hdr[:])
+		n := uint64(binary.LittleEndian.Uint32(hdr[:]))
+		if pos+spoolHeaderSize+n > head {
+			break
+		}
+		payload := make([]byte, n)
+		readRing(ring, pos+spoolHeaderSize, payload)
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(hdr[4:]) {
+			break
+		}
+		// Undecodable records are skipped like in the spool.
+		if r, err := decodeSpoolRecord(payload); err == nil {
+			records = append(records, r)
+		}
+		pos += spoolHeaderSize + n
+	}
+	return records, nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package eslog
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile is only implemented on Unix systems.
+func mapFile(*os.File, int) ([]byte, func() error, error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,130 @@
+package eslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// readMessages returns the messages of the records in the flight recorder file at path.
+func readMessages(t *testing.T, path string) []string {
+	t.Helper()
+	records, err := ReadFlightRecorder(path)
+	assert.NoError(t, err)
+	msgs := []string{}
+	for _, r := range records {
+		msgs = append(msgs, r.Message)
+	}
+	return msgs
+}
+
+func TestFlightRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flight")
+	fr, err := OpenFlightRecorder(path, FlightRecorderOptions{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, false, fr.Enabled(context.Background(), LevelTrace))
+	assert.Equal(t, true, fr.Enabled(context.Background(), slog.LevelDebug))
+
+	now := time.Now().Round(0)
+	r := slog.NewRecord(now, slog.LevelDebug, "query", 0)
+	r.AddAttrs(slog.Int("rows", 3))
+	h := fr.WithAttrs([]slog.Attr{slog.String("db", "users")}).WithGroup("req")
+	assert.NoError(t, h.Handle(context.Background(), r))
+	assert.NoError(t, fr.Close())
+
+	records, err := ReadFlightRecorder(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, true, records[0].Time.Equal(now))
+	assert.Equal(t, slog.LevelDebug, records[0].Level)
+	assert.Equal(t, "query", records[0].Message)
+	var attrs []string
+	records[0].Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a.String())
+		return true
+	})
+	assert.Equal(t, []string{"db=users", "req=[rows=3]"}, attrs)
+
+	assert.Equal(t, ErrHandlerClosed, fr.Handle(context.Background(), r))
+}
+
+func TestFlightRecorderWrapsAround(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flight")
+	fr, err := OpenFlightRecorder(path, FlightRecorderOptions{Size: 1000})
+	assert.NoError(t, err)
+	defer fr.Close()
+
+	for i := range 100 {
+		handleMessage(t, fr, fmt.Sprintf("record %02d", i))
+	}
+
+	msgs := readMessages(t, path)
+	if len(msgs) < 5 || len(msgs) >= 100 {
+		t.Fatalf("unexpected number of records: %d", len(msgs))
+	}
+	for i, msg := range msgs {
+		assert.Equal(t, fmt.Sprintf("record %02d", 100-len(msgs)+i), msg)
+	}
+
+	err = fr.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, string(make([]byte, 1000)), 0))
+	assert.Contains(t, err.Error(), "exceeds the flight recorder size")
+}
+
+func TestFlightRecorderReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flight")
+	fr, err := OpenFlightRecorder(path, FlightRecorderOptions{Size: 4096})
+	assert.NoError(t, err)
+	handleMessage(t, fr, "first run")
+	// The process dies without closing the recorder.
+
+	fr2, err := OpenFlightRecorder(path, FlightRecorderOptions{Size: 4096})
+	assert.NoError(t, err)
+	handleMessage(t, fr2, "second run")
+	assert.Equal(t, []string{"first run", "second run"}, readMessages(t, path))
+	assert.NoError(t, fr2.Close())
+	assert.NoError(t, fr.Close())
+
+	// A different size resets the file.
+	fr3, err := OpenFlightRecorder(path, FlightRecorderOptions{Size: 8192})
+	assert.NoError(t, err)
+	defer fr3.Close()
+	assert.Equal(t, []string{}, readMessages(t, path))
+}
+
+func TestReadFlightRecorderInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flight")
+	assert.NoError(t, os.WriteFile(path, []byte("no flight recorder"), 0o600))
+
+	_, err := ReadFlightRecorder(path)
+	assert.Equal(t, true, errors.Is(err, ErrFlightRecorderFormat))
+}
+
+func TestFlightRecorderConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flight")
+	fr, err := OpenFlightRecorder(path, FlightRecorderOptions{})
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelInfo)
+	defer logLevel.Set(slog.LevelDebug)
+	l := New(&Config{FlightRecorder: fr, out: buf})
+
+	l.Debug("cache miss")
+	l.Info("started")
+	l.Print("done\n")
+	assert.NoError(t, l.Close())
+
+	assert.NotContains(t, buf.String(), "cache miss")
+	assert.Contains(t, buf.String(), "msg=started")
+	assert.Contains(t, buf.String(), "done\n")
+	assert.Equal(t, []string{"cache miss", "started"}, readMessages(t, path))
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package eslog
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of f shared into memory, so writes end up in f.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
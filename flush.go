@@ -0,0 +1,57 @@
+package eslog
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// flusher is implemented by handlers which buffer records.
+type flusher interface {
+	Flush() error
+}
+
+// flushHandler flushes h if it buffers records.
+func flushHandler(h slog.Handler) error {
+	if f, ok := h.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// closeHandler closes h if it needs to be closed.
+func closeHandler(h slog.Handler) error {
+	if c, ok := h.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// flushHandlers flushes all handlers and joins their errors.
+func flushHandlers(handlers []slog.Handler) error {
+	var errs []error
+	for _, h := range handlers {
+		errs = append(errs, flushHandler(h))
+	}
+	return errors.Join(errs...)
+}
+
+// closeHandlers closes all handlers and joins their errors.
+func closeHandlers(handlers []slog.Handler) error {
+	var errs []error
+	for _, h := range handlers {
+		errs = append(errs, closeHandler(h))
+	}
+	return errors.Join(errs...)
+}
+
+// Flush writes all records buffered by the handlers of the Logger, e.g. by an AsyncHandler.
+func (l eSlogLogger) Flush() error {
+	return flushHandler(l.Handler())
+}
+
+// Close flushes and closes all handlers of the Logger which need to be closed, e.g. an
+// AsyncHandler. It is called by Fatal before the program exits.
+func (l eSlogLogger) Close() error {
+	return closeHandler(l.Handler())
+}
@@ -0,0 +1,91 @@
+package eslog
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Keys of the special fields of Google Cloud Logging.
+const (
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	gcpTraceKey          = "logging.googleapis.com/trace"
+	gcpSpanIDKey         = "logging.googleapis.com/spanId"
+	gcpTraceSampledKey   = "logging.googleapis.com/trace_sampled"
+)
+
+// Keys of the attributes with the trace context. GCPFormat and OTLPHandler write them as
+// trace fields.
+const (
+	TraceIDKey      = "trace_id"
+	SpanIDKey       = "span_id"
+	TraceSampledKey = "trace_sampled"
+)
+
+// gcpSeverities maps syslog severities to the severities of Google Cloud Logging.
+var gcpSeverities = map[int]string{
+	severityAlert:    "ALERT",
+	severityCritical: "CRITICAL",
+	severityError:    "ERROR",
+	severityWarning:  "WARNING",
+	severityNotice:   "NOTICE",
+	severityInfo:     "INFO",
+	severityDebug:    "DEBUG",
+}
+
+// gcpHandlerOptions returns opts with a ReplaceAttr writing the fields recognized by
+// Google Cloud Logging: severity, message, the source location and the trace fields.
+// Trace IDs are prefixed with "projects/$GOOGLE_CLOUD_PROJECT/traces/" if the variable is
+// set, so the logs are linked to Cloud Trace.
+func gcpHandlerOptions(opts *slog.HandlerOptions) *slog.HandlerOptions {
+	o := *opts
+	replace := opts.ReplaceAttr
+	project := os.Getenv("GOOGLE_CLOUD_PROJECT")
+
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return callReplaceAttr(replace, groups, a)
+		}
+		switch a.Key {
+		case slog.LevelKey:
+			level, ok := a.Value.Any().(slog.Level)
+			if !ok {
+				// An attribute of the record named "level".
+				return callReplaceAttr(replace, groups, a)
+			}
+			if level == LevelPrint {
+				return slog.Attr{}
+			}
+			return slog.String("severity", gcpSeverities[syslogSeverity(level)])
+		case slog.MessageKey:
+			a.Key = "message"
+		case slog.SourceKey:
+			a = callReplaceAttr(replace, groups, a)
+			if src, ok := a.Value.Any().(*slog.Source); ok {
+				return slog.Group(gcpSourceLocationKey, "file", src.File, "line", src.Line, "function", src.Function)
+			}
+		case TraceIDKey:
+			trace := a.Value.String()
+			if project != "" && !strings.HasPrefix(trace, "projects/") {
+				trace = "projects/" + project + "/traces/" + trace
+			}
+			return slog.String(gcpTraceKey, trace)
+		case SpanIDKey:
+			a.Key = gcpSpanIDKey
+		case TraceSampledKey:
+			a.Key = gcpTraceSampledKey
+		default:
+			return callReplaceAttr(replace, groups, a)
+		}
+		return a
+	}
+	return &o
+}
+
+// callReplaceAttr calls replace if it is set.
+func callReplaceAttr(replace func([]string, slog.Attr) slog.Attr, groups []string, a slog.Attr) slog.Attr {
+	if replace == nil {
+		return a
+	}
+	return replace(groups, a)
+}
@@ -0,0 +1,63 @@
+package eslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestGCPFormat(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "my-project")
+	buf := &bytes.Buffer{}
+	logLevel.Set(LevelTrace)
+	_, file, _, _ := runtime.Caller(0)
+	l := New(&Config{Format: GCPFormat, AddSource: true, SourceRoot: file[:len(file)-len("gcp_test.go")], out: buf})
+
+	l.Error("failed", TraceIDKey, "4bf92f3577b34da6", SpanIDKey, "00f067aa0ba902b7", TraceSampledKey, true,
+		slog.Group("req", TraceIDKey, "not a trace field"))
+	_, _, line, _ := runtime.Caller(0)
+
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	delete(m, "time")
+	expected := map[string]any{
+		"severity": "ERROR",
+		"message":  "failed",
+		gcpSourceLocationKey: map[string]any{
+			"file":     "gcp_test.go",
+			"line":     float64(line - 2),
+			"function": "github.com/steffakasid/eslog.TestGCPFormat",
+		},
+		gcpTraceKey:        "projects/my-project/traces/4bf92f3577b34da6",
+		gcpSpanIDKey:       "00f067aa0ba902b7",
+		gcpTraceSampledKey: true,
+		"req":              map[string]any{TraceIDKey: "not a trace field"},
+	}
+	assert.Equal(t, expected, m)
+}
+
+func TestGCPSeverity(t *testing.T) {
+	tests := map[slog.Level]string{
+		LevelTrace:         "DEBUG",
+		slog.LevelDebug:    "DEBUG",
+		slog.LevelInfo:     "INFO",
+		slog.LevelInfo + 1: "NOTICE",
+		slog.LevelWarn:     "WARNING",
+		slog.LevelError:    "ERROR",
+		LevelFatal:         "CRITICAL",
+		LevelFatal + 2:     "ALERT",
+	}
+
+	buf := &bytes.Buffer{}
+	h := slog.NewJSONHandler(buf, gcpHandlerOptions(&slog.HandlerOptions{Level: LevelTrace}))
+	for level, expected := range tests {
+		buf.Reset()
+		assert.NoError(t, h.Handle(t.Context(), slog.NewRecord(time.Time{}, level, "msg", 0)))
+		assert.Equal(t, `{"severity":"`+expected+`","message":"msg"}`+"\n", buf.String())
+	}
+}
@@ -0,0 +1,215 @@
+package eslog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GELFCompression selects the compression of GELF messages sent over UDP.
+type GELFCompression int
+
+const (
+	// GELFNoCompression sends uncompressed messages.
+	GELFNoCompression GELFCompression = iota
+	// GELFGzip compresses messages with gzip.
+	GELFGzip
+	// GELFZlib compresses messages with zlib.
+	GELFZlib
+)
+
+const (
+	// defaultGELFChunkSize fits into the MTU of most networks.
+	defaultGELFChunkSize = 1420
+	// gelfChunkHeaderSize is the size of the magic bytes, the message id, the sequence
+	// number and the sequence count in front of each chunk.
+	gelfChunkHeaderSize = 12
+	// gelfMaxChunks is the maximum number of chunks Graylog accepts for a message.
+	gelfMaxChunks = 128
+)
+
+// gelfInvalidFieldChars matches the characters not allowed in names of additional fields.
+var gelfInvalidFieldChars = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFOptions configures a GELFHandler.
+type GELFOptions struct {
+	// Network is "udp" or "tcp" (or their variants like "tcp4"). Defaults to "udp".
+	Network string
+	// Addr is the address of the GELF input, e.g. "graylog:12201".
+	Addr string
+	// Host is sent as host. Defaults to os.Hostname().
+	Host string
+	// Compression compresses messages sent over UDP. TCP messages are never compressed.
+	Compression GELFCompression
+	// ChunkSize is the maximum size of a UDP datagram. Larger messages are chunked.
+	// Defaults to 1420.
+	ChunkSize int
+	// Level is the minimum level of the handler. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+	// Timeout is used to connect and write. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// GELFHandler sends records as GELF 1.1 messages to Graylog. The first line of the message
+// is sent as short_message, multi-line messages as full_message, too. Attributes are sent
+// as additional fields prefixed with "_", keys of groups are joined with "_". UDP messages
+// larger than ChunkSize are chunked, TCP messages are terminated by a null byte.
+type GELFHandler struct {
+	opts  *GELFOptions
+	attrs attrState
+	conn  *reconnectingConn
+}
+
+// NewGELFHandler creates a GELFHandler and connects to the GELF input.
+func NewGELFHandler(opts GELFOptions) (*GELFHandler, error) {
+	if opts.Network == "" {
+		opts.Network = "udp"
+	}
+	if opts.Host == "" {
+		opts.Host, _ = os.Hostname()
+	}
+	if opts.ChunkSize <= gelfChunkHeaderSize {
+		opts.ChunkSize = defaultGELFChunkSize
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+
+	h := &GELFHandler{opts: &opts, conn: newReconnectingConn(opts.Network, opts.Addr, opts.Timeout)}
+	return h, h.conn.connect()
+}
+
+func (h *GELFHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+func (h *GELFHandler) Handle(_ context.Context, r slog.Record) error {
+	msg := h.appendMessage(nil, r)
+	if !strings.HasPrefix(h.opts.Network, "udp") {
+		return h.conn.write(append(msg, 0))
+	}
+
+	msg, err := h.compress(msg)
+	if err != nil {
+		return err
+	}
+	chunks, err := h.chunk(msg)
+	if err != nil {
+		return err
+	}
+	return h.conn.write(chunks...)
+}
+
+func (h *GELFHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &GELFHandler{opts: h.opts, attrs: h.attrs.withAttrs(attrs), conn: h.conn}
+}
+
+func (h *GELFHandler) WithGroup(name string) slog.Handler {
+	return &GELFHandler{opts: h.opts, attrs: h.attrs.withGroup(name), conn: h.conn}
+}
+
+// Close closes the connection to the GELF input.
+func (h *GELFHandler) Close() error {
+	return h.conn.close()
+}
+
+// appendMessage appends r as GELF message to b.
+func (h *GELFHandler) appendMessage(b []byte, r slog.Record) []byte {
+	short, _, multiline := strings.Cut(r.Message, "\n")
+	if short == "" {
+		short = "-"
+	}
+
+	b = append(b, `{"version":"1.1","host":`...)
+	b = appendJSONString(b, h.opts.Host)
+	b = append(b, `,"short_message":`...)
+	b = appendJSONString(b, short)
+	if multiline {
+		b = append(b, `,"full_message":`...)
+		b = appendJSONString(b, r.Message)
+	}
+	b = fmt.Appendf(b, `,"timestamp":%d.%03d,"level":%d`,
+		r.Time.Unix(), r.Time.Nanosecond()/int(time.Millisecond), syslogSeverity(r.Level))
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		b = append(b, `,"_file":`...)
+		b = appendJSONString(b, frame.File)
+		b = append(b, `,"_line":`...)
+		b = strconv.AppendInt(b, int64(frame.Line), 10)
+	}
+	for _, f := range h.attrs.recordFields(r) {
+		b = append(b, ',')
+		b = appendJSONString(b, gelfFieldName(f.name("_")))
+		b = append(b, ':')
+		b = appendJSONScalar(b, f.value)
+	}
+	return append(b, '}')
+}
+
+// compress compresses msg with the configured compression.
+func (h *GELFHandler) compress(msg []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch h.opts.Compression {
+	case GELFGzip:
+		w = gzip.NewWriter(&buf)
+	case GELFZlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		return msg, nil
+	}
+	if _, err := w.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// chunk splits msg into GELF chunks if it is larger than ChunkSize.
+func (h *GELFHandler) chunk(msg []byte) ([][]byte, error) {
+	if len(msg) <= h.opts.ChunkSize {
+		return [][]byte{msg}, nil
+	}
+
+	size := h.opts.ChunkSize - gelfChunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("eslog: GELF message of %d bytes needs more than %d chunks", len(msg), gelfMaxChunks)
+	}
+
+	id := rand.Uint64()
+	chunks := make([][]byte, 0, count)
+	for i := range count {
+		data := msg[i*size : min((i+1)*size, len(msg))]
+		chunk := make([]byte, 0, gelfChunkHeaderSize+len(data))
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = binary.BigEndian.AppendUint64(chunk, id)
+		chunk = append(chunk, byte(i), byte(count))
+		chunks = append(chunks, append(chunk, data...))
+	}
+	return chunks, nil
+}
+
+// gelfFieldName returns the name of the additional field for an attribute. Invalid
+// characters are replaced by "_". "_id" is reserved, so "id" is sent as "_id_".
+func gelfFieldName(name string) string {
+	name = gelfInvalidFieldChars.ReplaceAllLiteralString(name, "_")
+	if name == "id" {
+		return "_id_"
+	}
+	return "_" + name
+}
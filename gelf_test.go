@@ -0,0 +1,143 @@
+package eslog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// readGELF reads one GELF message from pc. Chunks are reassembled and the message is
+// decompressed.
+func readGELF(t *testing.T, pc net.PacketConn) map[string]any {
+	t.Helper()
+	var msg []byte
+	buf := make([]byte, 65536)
+	for chunks := map[byte][]byte{}; ; {
+		assert.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := pc.ReadFrom(buf)
+		assert.NoError(t, err)
+		if !bytes.HasPrefix(buf[:n], []byte{0x1e, 0x0f}) {
+			msg = append([]byte{}, buf[:n]...)
+			break
+		}
+		chunks[buf[10]] = append([]byte{}, buf[12:n]...)
+		if count := int(buf[11]); len(chunks) == count {
+			for i := range count {
+				msg = append(msg, chunks[byte(i)]...)
+			}
+			break
+		}
+	}
+
+	var r io.Reader = bytes.NewReader(msg)
+	var err error
+	switch {
+	case bytes.HasPrefix(msg, []byte{0x1f, 0x8b}):
+		r, err = gzip.NewReader(r)
+	case msg[0] == 0x78:
+		r, err = zlib.NewReader(r)
+	}
+	assert.NoError(t, err)
+	return decodeGELF(t, r)
+}
+
+func decodeGELF(t *testing.T, r io.Reader) map[string]any {
+	t.Helper()
+	var m map[string]any
+	assert.NoError(t, json.NewDecoder(r).Decode(&m))
+	return m
+}
+
+func newGELFTestRecord(msg string) slog.Record {
+	r := slog.NewRecord(time.Date(2026, 1, 2, 3, 4, 5, 123000000, time.UTC), slog.LevelError, msg, 0)
+	r.Add("status", 500, "id", "abc", "user name", "bob", slog.Group("req", "path", "/", "ok", false))
+	return r
+}
+
+func TestGELFHandlerUDP(t *testing.T) {
+	for name, compression := range map[string]GELFCompression{"none": GELFNoCompression, "gzip": GELFGzip, "zlib": GELFZlib} {
+		t.Run(name, func(t *testing.T) {
+			pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			defer func() { _ = pc.Close() }()
+
+			h, err := NewGELFHandler(GELFOptions{Addr: pc.LocalAddr().String(), Host: "host", Compression: compression})
+			assert.NoError(t, err)
+			defer func() { _ = h.Close() }()
+
+			handler := h.WithAttrs([]slog.Attr{slog.String("service", "api")}).WithGroup("http")
+			assert.NoError(t, handler.Handle(context.Background(), newGELFTestRecord("request failed\nstack")))
+
+			expected := map[string]any{
+				"version":         "1.1",
+				"host":            "host",
+				"short_message":   "request failed",
+				"full_message":    "request failed\nstack",
+				"timestamp":       1767323045.123,
+				"level":           float64(3),
+				"_service":        "api",
+				"_http_status":    float64(500),
+				"_http_id":        "abc",
+				"_http_user_name": "bob",
+				"_http_req_path":  "/",
+				"_http_req_ok":    "false",
+			}
+			assert.Equal(t, expected, readGELF(t, pc))
+		})
+	}
+}
+
+func TestGELFHandlerChunking(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() { _ = pc.Close() }()
+
+	h, err := NewGELFHandler(GELFOptions{Addr: pc.LocalAddr().String(), ChunkSize: 100})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	msg := strings.Repeat("a long message ", 50)
+	assert.NoError(t, h.Handle(context.Background(), newGELFTestRecord(msg)))
+	assert.Equal(t, any(msg), readGELF(t, pc)["short_message"])
+
+	// Messages needing more than 128 chunks are rejected.
+	err = h.Handle(context.Background(), newGELFTestRecord(strings.Repeat("x", 128*88+1)))
+	assert.Contains(t, err.Error(), "more than 128 chunks")
+}
+
+func TestGELFHandlerTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+
+	h, err := NewGELFHandler(GELFOptions{Network: "tcp", Addr: ln.Addr().String(), Compression: GELFGzip})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	conn, err := ln.Accept()
+	assert.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	assert.NoError(t, h.Handle(context.Background(), newGELFTestRecord("first")))
+	assert.NoError(t, h.Handle(context.Background(), newGELFTestRecord("second")))
+
+	r := bufio.NewReader(conn)
+	for _, expected := range []string{"first", "second"} {
+		msg, err := r.ReadBytes(0)
+		assert.NoError(t, err)
+		m := decodeGELF(t, bytes.NewReader(bytes.TrimSuffix(msg, []byte{0})))
+		assert.Equal(t, any(expected), m["short_message"])
+		assert.Equal(t, nil, m["full_message"])
+	}
+}
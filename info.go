@@ -1,13 +1,10 @@
 package eslog
 
-import (
-	"fmt"
-	"strings"
-)
+import "log/slog"
 
 // Infof logs at [LevelInfo]. Multiple args are joined with "  ".
 func Info(args ...any) {
-	Logger.Info(strings.Join(convertAnyToString(args...), " "))
+	Logger.logArgs(slog.LevelInfo, args...)
 }
 
 // Infof logs at [LevelInfo]. The function uses fmt.Sprintf with given format and args
@@ -19,15 +16,17 @@ func Infof(format string, args ...any) {
 // Infof logs at [LevelInfo]. The function uses fmt.Sprintf with given format and args
 // and log it.
 func (l eSlogLogger) Infof(format string, args ...any) {
-	l.Info(fmt.Sprintf(format, args...))
+	l.logf(slog.LevelInfo, format, args...)
 }
 
-// InfoLn logs at [LevelInfo] and appends a newline.
-func InfoLn(msg string, args ...any) {
-	Logger.InfoLn(msg, args...)
+// InfoLn logs at [LevelInfo]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func InfoLn(args ...any) {
+	Logger.InfoLn(args...)
 }
 
-func (l eSlogLogger) InfoLn(msg string, args ...any) {
-	args = append(args, "\n")
-	Logger.Info(msg, args...)
+// InfoLn logs at [LevelInfo]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func (l eSlogLogger) InfoLn(args ...any) {
+	l.logln(slog.LevelInfo, args...)
 }
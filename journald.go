@@ -0,0 +1,127 @@
+package eslog
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// defaultJournaldAddr is the socket of the journald native protocol.
+const defaultJournaldAddr = "/run/systemd/journal/socket"
+
+// JournaldOptions configures a JournaldHandler.
+type JournaldOptions struct {
+	// Addr is the path of the journald socket. Defaults to "/run/systemd/journal/socket".
+	Addr string
+	// SyslogIdentifier is sent as SYSLOG_IDENTIFIER. Defaults to the name of the executable.
+	SyslogIdentifier string
+	// Level is the minimum level of the handler. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+}
+
+// JournaldHandler sends records to journald using its native protocol. The message is sent
+// as MESSAGE, the level as PRIORITY and the source location as CODE_FILE, CODE_LINE and
+// CODE_FUNC. Attributes are sent as fields with uppercase keys, keys of groups are joined
+// with "_". Payloads too large for a datagram are passed as memfd.
+type JournaldHandler struct {
+	opts  *JournaldOptions
+	attrs attrState
+	conn  *reconnectingConn
+}
+
+// NewJournaldHandler creates a JournaldHandler and connects to the journald socket.
+func NewJournaldHandler(opts JournaldOptions) (*JournaldHandler, error) {
+	if opts.Addr == "" {
+		opts.Addr = defaultJournaldAddr
+	}
+	if opts.SyslogIdentifier == "" {
+		opts.SyslogIdentifier = filepath.Base(os.Args[0])
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+
+	h := &JournaldHandler{opts: &opts, conn: newReconnectingConn("unixgram", opts.Addr, 0)}
+	return h, h.conn.connect()
+}
+
+func (h *JournaldHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+func (h *JournaldHandler) Handle(_ context.Context, r slog.Record) error {
+	b := appendJournalField(nil, "MESSAGE", r.Message)
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(syslogSeverity(r.Level)))
+	b = appendJournalField(b, "SYSLOG_IDENTIFIER", h.opts.SyslogIdentifier)
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		b = appendJournalField(b, "CODE_FILE", frame.File)
+		b = appendJournalField(b, "CODE_LINE", strconv.Itoa(frame.Line))
+		b = appendJournalField(b, "CODE_FUNC", frame.Function)
+	}
+	for _, f := range h.attrs.recordFields(r) {
+		if name := journalFieldName(f.name("_")); name != "" {
+			b = appendJournalField(b, name, valueString(f.value))
+		}
+	}
+
+	return h.conn.do(func(conn net.Conn) error {
+		return writeJournal(conn, b)
+	})
+}
+
+func (h *JournaldHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &JournaldHandler{opts: h.opts, attrs: h.attrs.withAttrs(attrs), conn: h.conn}
+}
+
+func (h *JournaldHandler) WithGroup(name string) slog.Handler {
+	return &JournaldHandler{opts: h.opts, attrs: h.attrs.withGroup(name), conn: h.conn}
+}
+
+// Close closes the connection to journald.
+func (h *JournaldHandler) Close() error {
+	return h.conn.close()
+}
+
+// appendJournalField appends a field in the format of the native protocol to b. Values
+// containing newlines are sent with their length as little endian uint64.
+func appendJournalField(b []byte, name, value string) []byte {
+	b = append(b, name...)
+	if !strings.Contains(value, "\n") {
+		b = append(b, '=')
+		b = append(b, value...)
+		return append(b, '\n')
+	}
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// journalFieldName returns name as journal field name: uppercase letters, digits and
+// underscores, not starting with an underscore or digit and at most 64 characters. It
+// returns "" if nothing is left.
+func journalFieldName(name string) string {
+	b := make([]byte, 0, len(name))
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c >= 'a' && c <= 'z':
+			b = append(b, c-'a'+'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			b = append(b, c)
+		default:
+			b = append(b, '_')
+		}
+	}
+	name = strings.TrimLeft(string(b), "_0123456789")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
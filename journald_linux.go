@@ -0,0 +1,108 @@
+package eslog
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// memfdCreateTrap is the number of the memfd_create syscall, which is not defined by
+// package syscall for all architectures.
+var memfdCreateTrap = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+const (
+	mfdCloexec      = 0x1
+	mfdAllowSealing = 0x2
+	fAddSeals       = 1033
+	// fSeals seals the size and the content of a memfd (F_SEAL_SEAL, F_SEAL_SHRINK,
+	// F_SEAL_GROW and F_SEAL_WRITE), as journald requires.
+	fSeals = 0x1 | 0x2 | 0x4 | 0x8
+)
+
+// writeJournal writes payload to the journald socket. If it is too large for a datagram it
+// is written to a memfd which is passed to journald instead.
+func writeJournal(conn net.Conn, payload []byte) error {
+	_, err := conn.Write(payload)
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return err
+	}
+	rc, err := uc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	f, err := journalPayloadFile(payload)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	// WriteMsgUnix refuses connected datagram sockets, so sendmsg is called directly.
+	rights := syscall.UnixRights(int(f.Fd()))
+	writeErr := rc.Write(func(fd uintptr) bool {
+		err = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return err != syscall.EAGAIN
+	})
+	return errors.Join(writeErr, err)
+}
+
+// journalPayloadFile returns a sealed memfd containing payload. If memfds are not
+// available an unlinked file in /dev/shm is used like libsystemd does.
+func journalPayloadFile(payload []byte) (*os.File, error) {
+	f, err := memfdCreate("eslog-journal")
+	memfd := err == nil
+	if !memfd {
+		if f, err = os.CreateTemp("/dev/shm", "eslog-journal-"); err != nil {
+			return nil, err
+		}
+		_ = os.Remove(f.Name())
+	}
+
+	if _, err := f.Write(payload); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if memfd {
+		if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), fAddSeals, fSeals); errno != 0 {
+			_ = f.Close()
+			return nil, errno
+		}
+	}
+	return f, nil
+}
+
+func memfdCreate(name string) (*os.File, error) {
+	trap, ok := memfdCreateTrap[runtime.GOARCH]
+	if !ok {
+		return nil, syscall.ENOSYS
+	}
+	p, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(p)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return os.NewFile(fd, name), nil
+}
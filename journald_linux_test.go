@@ -0,0 +1,116 @@
+package eslog
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// listenJournal creates a stand-in for the journald socket.
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	addr := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, addr
+}
+
+// readJournal reads one payload from conn. Payloads passed as file descriptor are read from
+// the file.
+func readJournal(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 64*1024)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	assert.NoError(t, err)
+	if oobn == 0 {
+		return string(buf[:n])
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	assert.NoError(t, err)
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	assert.NoError(t, err)
+	link, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(fds[0]))
+	assert.NoError(t, err)
+	assert.Contains(t, link, "memfd:eslog-journal")
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer func() { _ = f.Close() }()
+	// journald maps the file, the offset is still at the end of the payload.
+	_, err = f.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	payload, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	return string(payload)
+}
+
+func TestJournaldHandler(t *testing.T) {
+	conn, addr := listenJournal(t)
+	h, err := NewJournaldHandler(JournaldOptions{Addr: addr, SyslogIdentifier: "app"})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	pc, _, _, _ := runtime.Caller(0)
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "disk full", pc)
+	r.Add("mount", "/var", "stack", "line 1\nline 2", "_private", true)
+	handler := h.WithAttrs([]slog.Attr{slog.String("request-id", "42")}).WithGroup("db")
+	assert.NoError(t, handler.Handle(context.Background(), r))
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	multiline := "line 1\nline 2"
+	length := binary.LittleEndian.AppendUint64(nil, uint64(len(multiline)))
+	expected := "MESSAGE=disk full\n" +
+		"PRIORITY=4\n" +
+		"SYSLOG_IDENTIFIER=app\n" +
+		"CODE_FILE=" + frame.File + "\n" +
+		"CODE_LINE=" + strconv.Itoa(frame.Line) + "\n" +
+		"CODE_FUNC=" + frame.Function + "\n" +
+		"REQUEST_ID=42\n" +
+		"DB_MOUNT=/var\n" +
+		"DB_STACK\n" + string(length) + multiline + "\n" +
+		"DB__PRIVATE=true\n"
+	assert.Equal(t, expected, readJournal(t, conn))
+}
+
+func TestJournaldHandlerLargePayload(t *testing.T) {
+	conn, addr := listenJournal(t)
+	h, err := NewJournaldHandler(JournaldOptions{Addr: addr})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	// Larger than the maximum datagram size, so the payload is passed as memfd.
+	msg := strings.Repeat("x", 4*1024*1024)
+	r := slog.NewRecord(time.Now(), slog.LevelError, msg, 0)
+	assert.NoError(t, h.Handle(context.Background(), r))
+
+	payload := readJournal(t, conn)
+	assert.Equal(t, true, strings.HasPrefix(payload, "MESSAGE="+msg+"\nPRIORITY=3\n"))
+}
+
+func TestJournalFieldName(t *testing.T) {
+	tests := map[string]string{
+		"user":                  "USER",
+		"http.status-code":      "HTTP_STATUS_CODE",
+		"_SYSTEMD_UNIT":         "SYSTEMD_UNIT",
+		"1st":                   "ST",
+		"äöü":                   "",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, journalFieldName(name))
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package eslog
+
+import "net"
+
+// writeJournal writes payload to the journald socket. journald only exists on Linux, so
+// large payloads can't be passed as memfd.
+func writeJournal(conn net.Conn, payload []byte) error {
+	_, err := conn.Write(payload)
+	return err
+}
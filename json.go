@@ -0,0 +1,82 @@
+package eslog
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// appendJSONString appends s as JSON string to b. Invalid UTF-8 is replaced by U+FFFD.
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, "\ufffd"...)
+		} else {
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(b, '"')
+}
+
+// appendJSONScalar appends v as JSON number if it is numeric and as JSON string otherwise.
+// Formats like GELF only allow strings and numbers as field values.
+func appendJSONScalar(b []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return strconv.AppendInt(b, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(b, v.Uint64(), 10)
+	case slog.KindFloat64:
+		if f := v.Float64(); !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.AppendFloat(b, f, 'g', -1, 64)
+		}
+	}
+	return appendJSONString(b, valueString(v))
+}
+
+// appendJSONValue appends v as JSON value to b like slog.JSONHandler does: numbers and
+// booleans as JSON values, durations in nanoseconds, errors as their message and other
+// values marshalled with encoding/json.
+func appendJSONValue(b []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		return appendJSONScalar(b, v)
+	case slog.KindBool:
+		return strconv.AppendBool(b, v.Bool())
+	case slog.KindDuration:
+		return strconv.AppendInt(b, int64(v.Duration()), 10)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return appendJSONString(b, err.Error())
+		}
+		if data, err := json.Marshal(v.Any()); err == nil {
+			return append(b, data...)
+		}
+	}
+	return appendJSONString(b, valueString(v))
+}
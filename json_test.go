@@ -0,0 +1,41 @@
+package eslog
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{"", "plain", `quote " and \ backslash`, "new\nline\ttab\r", "\x00\x1f", "äöü €", "invalid \xff utf-8"} {
+		var decoded string
+		assert.NoError(t, json.Unmarshal(appendJSONString(nil, s), &decoded))
+		if s == "invalid \xff utf-8" {
+			s = "invalid \ufffd utf-8"
+		}
+		assert.Equal(t, s, decoded)
+	}
+}
+
+func TestAppendJSONScalar(t *testing.T) {
+	tests := []struct {
+		value    slog.Value
+		expected string
+	}{
+		{slog.IntValue(-42), `-42`},
+		{slog.Uint64Value(42), `42`},
+		{slog.Float64Value(1.5), `1.5`},
+		{slog.Float64Value(math.Inf(1)), `"+Inf"`},
+		{slog.BoolValue(true), `"true"`},
+		{slog.DurationValue(time.Second), `"1s"`},
+		{slog.TimeValue(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)), `"2026-01-02T03:04:05Z"`},
+		{slog.AnyValue([]int{1, 2}), `"[1 2]"`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, string(appendJSONScalar(nil, tt.value)))
+	}
+}
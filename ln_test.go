@@ -0,0 +1,51 @@
+package eslog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// timePattern matches the time attribute of the text and JSON format.
+var timePattern = regexp.MustCompile(`time=\S+ |"time":"[^"]*",`)
+
+func TestLn(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		logFunc  func(l *eSlogLogger, args ...any)
+		args     []any
+		expected string
+	}{
+		{"debug text", TextFormat, (*eSlogLogger).DebugLn, []any{"a", "b"}, "level=DEBUG msg=\"a b\"\n"},
+		{"info text", TextFormat, (*eSlogLogger).InfoLn, []any{"count:", 3, true}, "level=INFO msg=\"count: 3 true\"\n"},
+		{"warn text", TextFormat, (*eSlogLogger).WarnLn, []any{"single"}, "level=WARN msg=single\n"},
+		{"error text", TextFormat, (*eSlogLogger).ErrorLn, []any{"error:", errors.New("boom")}, "level=ERROR msg=\"error: boom\"\n"},
+		{"empty text", TextFormat, (*eSlogLogger).InfoLn, nil, "level=INFO msg=\"\"\n"},
+		{"debug json", JSONFormat, (*eSlogLogger).DebugLn, []any{"a", "b"}, "{\"level\":\"DEBUG\",\"msg\":\"a b\"}\n"},
+		{"info json", JSONFormat, (*eSlogLogger).InfoLn, []any{"count:", 3, true}, "{\"level\":\"INFO\",\"msg\":\"count: 3 true\"}\n"},
+		{"warn json", JSONFormat, (*eSlogLogger).WarnLn, []any{"single"}, "{\"level\":\"WARN\",\"msg\":\"single\"}\n"},
+		{"error json", JSONFormat, (*eSlogLogger).ErrorLn, []any{"error:", errors.New("boom")}, "{\"level\":\"ERROR\",\"msg\":\"error: boom\"}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := New(&Config{Format: tt.format, out: buf})
+			logLevel.Set(slog.LevelDebug)
+
+			tt.logFunc(l, tt.args...)
+
+			assert.Equal(t, tt.expected, timePattern.ReplaceAllString(buf.String(), ""))
+		})
+	}
+}
+
+func TestSprintln(t *testing.T) {
+	assert.Equal(t, "a b 1 2", sprintln("a", "b", 1, 2))
+	assert.Equal(t, "", sprintln())
+}
@@ -1,16 +1,23 @@
 package eslog
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"reflect"
+	"strings"
+	"time"
 )
 
 // eSlogLogger is used to extend slog.
 type eSlogLogger struct {
 	*slog.Logger
-	config *Config
+	config       *Config
+	onPrintError func(err error)
+	// sampling is the handler of Config.Sampling, nil if sampling is not configured.
+	sampling *samplingHandler
 }
 
 // Logger is the default logger which extends slog.
@@ -44,27 +51,77 @@ func initLogger(cfg *Config) *eSlogLogger {
 	}
 
 	opts := &slog.HandlerOptions{
-		Level: logLevel,
+		Level:     logLevel,
+		AddSource: cfg.AddSource,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-			if a.Key == slog.LevelKey {
-				level := a.Value.Any().(slog.Level)
+			if a.Key == slog.SourceKey && len(groups) == 0 && cfg.SourceRoot != "" {
+				if src, ok := a.Value.Any().(*slog.Source); ok {
+					a.Value = slog.AnyValue(trimSource(src, cfg.SourceRoot))
+				}
+			}
+			// Attributes of the record named "level" aren't a slog.Level.
+			if level, ok := a.Value.Any().(slog.Level); ok && a.Key == slog.LevelKey {
 				// Drop level attribute for sentinel LevelPrint.
 				if level == LevelPrint {
 					return slog.Attr{}
 				}
-				levelLabel, exists := levelNames[level]
-				if !exists {
-					levelLabel = level.String()
-				}
-				a.Value = slog.StringValue(levelLabel)
+				a.Value = slog.StringValue(levelName(level))
 			}
 			return a
 		},
 	}
 
+	if cfg.FingersCrossed != nil {
+		// The handlers have to write the buffered records, the level of the Logger is
+		// applied by the fingersCrossedHandler.
+		opts.Level = minLeveler{logLevel, fingersCrossedBufferLevel(*cfg.FingersCrossed)}
+	}
+
+	out := cfg.out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	onPrintError := cfg.OnPrintError
+	if onPrintError == nil {
+		onPrintError = PrintErrorOnce(os.Stderr)
+	}
+
+	var handler slog.Handler = &printAwareHandler{h: newWriterHandler(cfg.Format, out, opts, cfg.PriorityPrefix), w: out}
+	if len(cfg.Sinks) > 0 {
+		handlers := make([]slog.Handler, 0, len(cfg.Sinks))
+		for _, sink := range cfg.Sinks {
+			handlers = append(handlers, newSinkHandler(sink, *opts))
+		}
+		handler = &multiHandler{handlers: handlers}
+	}
+	if cfg.FingersCrossed != nil {
+		handler = newFingersCrossedHandler(handler, logLevel, *cfg.FingersCrossed)
+	}
+	var sampling *samplingHandler
+	if cfg.Sampling != nil {
+		sampling = newSamplingHandler(handler, *cfg.Sampling)
+		handler = sampling
+	}
+	if cfg.Dedup != nil {
+		handler = newDedupHandler(handler, *cfg.Dedup)
+	}
+	if cfg.FlightRecorder != nil {
+		handler = &multiHandler{handlers: []slog.Handler{handler, &noPrintHandler{h: cfg.FlightRecorder}}}
+	}
+	if cfg.Redact != nil {
+		handler = newRedactHandler(handler, *cfg.Redact)
+	}
+	if len(cfg.ErrorRules) > 0 {
+		// The rules are applied before redaction, which may replace errors by strings.
+		handler = newErrorRulesHandler(handler, cfg.ErrorRules)
+	}
+
 	return &eSlogLogger{
-		Logger: slog.New(&printAwareHandler{h: slog.NewTextHandler(cfg.out, opts), w: cfg.out}),
-		config: cfg,
+		Logger:       slog.New(handler),
+		config:       cfg,
+		onPrintError: onPrintError,
+		sampling:     sampling,
 	}
 }
 
@@ -76,39 +133,70 @@ func Field(key string, value any) slog.Attr {
 // testing purposes or to swich logging to os.Stderr. In fact that function reinitializes
 // the Logger.
 func (l *eSlogLogger) SetOutput(w io.Writer) {
-	cfg := Config{
-		out: w,
-	}
+	cfg := *l.config
+	cfg.out = w
 	Logger = initLogger(&cfg)
 }
 
 // SetLogLevel sets the LogLevel of the Logger
 func (l *eSlogLogger) SetLogLevel(lvl string) error {
-	return logLevel.UnmarshalText([]byte(lvl))
+	level, err := ParseText(lvl)
+	if err != nil {
+		return err
+	}
+	logLevel.Set(level)
+	return nil
 }
 
+// ParseText parses a level name. Besides the names known by slog the custom level names
+// (e.g. "TRACE" and "FATAL") are accepted case-insensitively.
 func ParseText(text string) (slog.Level, error) {
+	for leveler, name := range levelNames {
+		if strings.EqualFold(name, text) {
+			return leveler.Level(), nil
+		}
+	}
 	var level slog.Level
 	err := level.UnmarshalText([]byte(text))
 	return level, err
 }
 
 // LogIfError check the given error. If error is nil nothing is logged. If error is not
-// nil the loggerFunc is used to log the args. The error is not automatically added to
-// args except args are empty.
+// nil the loggerFunc is used to log the args, or the error if args are empty. If loggerFunc
+// is Info, Warn, Error or Fatal the attribute created by Err(err) is added to the record.
+// Other functions like Print get the args unchanged. Prefer CheckErr, which doesn't need a
+// loggerFunc and reports whether err is not nil.
 func LogIfError(err error, loggerFunc func(args ...any), args ...any) {
-	if err != nil {
-		if len(args) == 0 {
-			loggerFunc(err)
-		} else {
-			loggerFunc(args...)
-		}
+	if err == nil {
+		return
+	}
+	if len(args) == 0 {
+		args = []any{err}
+	}
+	level, ok := logArgsLevels[reflect.ValueOf(loggerFunc).Pointer()]
+	if !ok {
+		loggerFunc(args...)
+		return
 	}
+	Logger.logArgs(level, append(args[:len(args):len(args)], Err(err))...)
+	if level == LevelFatal {
+		Logger.exit()
+	}
+}
+
+// logArgsLevels maps the functions logging their args with logArgs to their level, so
+// LogIfError can add the error as attribute.
+var logArgsLevels = map[uintptr]slog.Level{
+	reflect.ValueOf(Info).Pointer():  slog.LevelInfo,
+	reflect.ValueOf(Warn).Pointer():  slog.LevelWarn,
+	reflect.ValueOf(Error).Pointer(): slog.LevelError,
+	reflect.ValueOf(Fatal).Pointer(): LevelFatal,
 }
 
 // LogIfErrorf checks the given error. If error is nil nothing is logged. If error is not
 // nil the loggerFuncf is used with the given format to print the given args. The error is
-// not automatically added to args except args are empty.
+// not automatically added to args except args are empty. Prefer CheckErr, which always
+// logs the error as attribute.
 func LogIfErrorf(err error, loggerFuncf func(format string, args ...any), format string, args ...any) {
 	if err != nil {
 		if len(args) == 0 {
@@ -119,6 +207,67 @@ func LogIfErrorf(err error, loggerFuncf func(format string, args ...any), format
 	}
 }
 
+// log creates a record with msg and args and passes it to the handler if level is enabled.
+func (l eSlogLogger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if !l.Handler().Enabled(ctx, level) {
+		return
+	}
+	var pc uintptr
+	if l.config.AddSource {
+		pc = callerPC()
+	}
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// logf logs the message built by fmt.Sprintf at level. The message is only formatted if
+// level is enabled. Attributes attached to errors in args by WrapErr are added to the
+// record.
+func (l eSlogLogger) logf(level slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	if !l.Handler().Enabled(ctx, level) {
+		return
+	}
+	l.log(ctx, level, fmt.Sprintf(format, args...), errorArgAttrs(args)...)
+}
+
+// logArgs logs args joined with " " at level. Args of type slog.Attr, e.g. created by
+// Err, are added as attributes instead, like the attributes attached to errors by WrapErr.
+// The args are only converted if level is enabled.
+func (l eSlogLogger) logArgs(level slog.Level, args ...any) {
+	ctx := context.Background()
+	if !l.Handler().Enabled(ctx, level) {
+		return
+	}
+	var msgArgs, attrs []any
+	for _, arg := range args {
+		if a, ok := arg.(slog.Attr); ok {
+			attrs = append(attrs, a)
+		} else {
+			msgArgs = append(msgArgs, arg)
+		}
+	}
+	attrs = append(attrs, errorArgAttrs(args)...)
+	l.log(ctx, level, strings.Join(convertAnyToString(msgArgs...), " "), attrs...)
+}
+
+// logln logs args formatted by sprintln at level. The args are only formatted if level is
+// enabled. Attributes attached to errors in args by WrapErr are added to the record.
+func (l eSlogLogger) logln(level slog.Level, args ...any) {
+	ctx := context.Background()
+	if !l.Handler().Enabled(ctx, level) {
+		return
+	}
+	l.log(ctx, level, sprintln(args...), errorArgAttrs(args)...)
+}
+
+// sprintln formats args like fmt.Sprintln but without the trailing newline.
+func sprintln(args ...any) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
+
 // convertAnyToString converts all args of type any to string and
 // returns them as []string
 func convertAnyToString(args ...any) (strArr []string) {
@@ -0,0 +1,73 @@
+package eslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// disabledLogFuncs are called with a logger at LevelError, so they must not allocate.
+var disabledLogFuncs = map[string]func(l *eSlogLogger, args []any){
+	"Debug":   func(l *eSlogLogger, args []any) { l.log(context.Background(), slog.LevelDebug, "debug", args...) },
+	"Debugf":  func(l *eSlogLogger, args []any) { l.Debugf("debug %s %d", args...) },
+	"DebugLn": func(l *eSlogLogger, args []any) { l.DebugLn(args...) },
+	"Info":    func(l *eSlogLogger, args []any) { l.logArgs(slog.LevelInfo, args...) },
+	"Infof":   func(l *eSlogLogger, args []any) { l.Infof("info %s %d", args...) },
+	"InfoLn":  func(l *eSlogLogger, args []any) { l.InfoLn(args...) },
+	"Warnf":   func(l *eSlogLogger, args []any) { l.Warnf("warn %s %d", args...) },
+	"WarnLn":  func(l *eSlogLogger, args []any) { l.WarnLn(args...) },
+}
+
+func newDisabledLogger() (*eSlogLogger, []any) {
+	l := New(&Config{out: io.Discard})
+	logLevel.Set(slog.LevelError)
+	return l, []any{"value", 42}
+}
+
+func TestDisabledLevelsDontAllocate(t *testing.T) {
+	l, args := newDisabledLogger()
+	defer logLevel.Set(slog.LevelDebug)
+
+	for name, logFunc := range disabledLogFuncs {
+		t.Run(name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() { logFunc(l, args) })
+			if allocs != 0 {
+				t.Errorf("expected 0 allocations, got %v", allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	l, args := newDisabledLogger()
+	defer logLevel.Set(slog.LevelDebug)
+
+	for name, logFunc := range disabledLogFuncs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				logFunc(l, args)
+			}
+		})
+	}
+}
+
+func BenchmarkEnabled(b *testing.B) {
+	l := New(&Config{out: io.Discard})
+	logLevel.Set(slog.LevelDebug)
+	args := []any{"value", 42}
+
+	b.Run("Debugf", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			l.Debugf("debug %s %d", args...)
+		}
+	})
+	b.Run("DebugLn", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			l.DebugLn(args...)
+		}
+	})
+}
@@ -96,6 +96,9 @@ func TestParseText(t *testing.T) {
 		{"warn lowercase", "warn", slog.LevelWarn, false},
 		{"ERROR uppercase", "ERROR", slog.LevelError, false},
 		{"error lowercase", "error", slog.LevelError, false},
+		{"TRACE uppercase", "TRACE", eslog.LevelTrace, false},
+		{"trace lowercase", "trace", eslog.LevelTrace, false},
+		{"FATAL uppercase", "FATAL", eslog.LevelFatal, false},
 		{"invalid level", "invalid", slog.Level(0), true},
 		{"empty string", "", slog.Level(0), true},
 	}
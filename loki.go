@@ -0,0 +1,219 @@
+package eslog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultLokiURL is the push API of a local Loki.
+const defaultLokiURL = "http://localhost:3100/loki/api/v1/push"
+
+// lokiLevelLabel promotes the level of records to a label if it is one of LokiOptions.Labels.
+const lokiLevelLabel = "level"
+
+// lokiInvalidLabelChars matches the characters not allowed in label names.
+var lokiInvalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// LokiOptions configures a LokiHandler.
+type LokiOptions struct {
+	// URL is the push API endpoint. Defaults to "http://localhost:3100/loki/api/v1/push".
+	URL string
+	// Labels are the keys of attributes which are promoted to stream labels, e.g.
+	// "service". Keys of grouped attributes are joined with ".". "level" promotes the level
+	// of the record. All other attributes are kept in the log line.
+	Labels []string
+	// StaticLabels are added to all streams, e.g. {"job": "api"}.
+	StaticLabels map[string]string
+	// TenantID is sent as X-Scope-OrgID header if set.
+	TenantID string
+	// Headers are added to each request, e.g. for authentication.
+	Headers map[string]string
+	// Compress compresses the requests with gzip.
+	Compress bool
+	// Level is the minimum level of the handler. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+	// Batch configures batching and retries.
+	Batch BatchOptions
+	// Client is used to send the requests. Defaults to a client with a timeout of 10s.
+	Client *http.Client
+}
+
+// LokiHandler pushes records to Grafana Loki. The attributes listed in LokiOptions.Labels
+// become stream labels, the message and the other attributes are written to the line in
+// logfmt. Records are sent in batches in the background, grouped by stream. Call Close
+// before the program exits.
+type LokiHandler struct {
+	opts   *LokiOptions
+	labels map[string]struct{}
+	attrs  attrState
+	batch  *batcher[lokiEntry]
+}
+
+// lokiEntry is a record with its stream labels.
+type lokiEntry struct {
+	// stream is the JSON object of the stream labels, which identifies the stream.
+	stream string
+	time   int64
+	line   string
+}
+
+// NewLokiHandler creates a LokiHandler and starts its background goroutine.
+func NewLokiHandler(opts LokiOptions) *LokiHandler {
+	if opts.URL == "" {
+		opts.URL = defaultLokiURL
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	headers := make(map[string]string, len(opts.Headers)+1)
+	for key, value := range opts.Headers {
+		headers[key] = value
+	}
+	if opts.TenantID != "" {
+		headers["X-Scope-OrgID"] = opts.TenantID
+	}
+
+	labels := make(map[string]struct{}, len(opts.Labels))
+	for _, label := range opts.Labels {
+		labels[label] = struct{}{}
+	}
+	h := &LokiHandler{opts: &opts, labels: labels}
+	encode := func(entries []lokiEntry) []byte {
+		return appendLokiPush(nil, entries)
+	}
+	h.batch = newBatcher(opts.Batch, encode, func(body []byte) error {
+		return postHTTP(opts.Client, opts.URL, "application/json", headers, body, opts.Compress)
+	})
+	return h
+}
+
+func (h *LokiHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle adds r to the current batch.
+func (h *LokiHandler) Handle(_ context.Context, r slog.Record) error {
+	labels := make(map[string]string, len(h.opts.StaticLabels)+len(h.labels))
+	for name, value := range h.opts.StaticLabels {
+		labels[lokiLabelName(name)] = value
+	}
+
+	var line []byte
+	if _, ok := h.labels[lokiLevelLabel]; ok {
+		labels[lokiLevelLabel] = strings.ToLower(levelName(r.Level))
+	} else {
+		line = append(line, "level="+levelName(r.Level)+" "...)
+	}
+	line = appendLogfmt(line, []field{{key: slog.MessageKey, value: slog.StringValue(r.Message)}})
+
+	fields := h.attrs.recordFields(r)
+	lineFields := fields[:0:0]
+	for _, f := range fields {
+		if _, ok := h.labels[f.name(".")]; ok {
+			labels[lokiLabelName(f.name("."))] = valueString(f.value)
+		} else {
+			lineFields = append(lineFields, f)
+		}
+	}
+	if len(lineFields) > 0 {
+		line = appendLogfmt(append(line, ' '), lineFields)
+	}
+
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	e := lokiEntry{stream: string(appendLokiStream(nil, labels)), time: t.UnixNano(), line: string(line)}
+	return h.batch.add(e, len(e.stream)+len(e.line))
+}
+
+func (h *LokiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LokiHandler{opts: h.opts, labels: h.labels, attrs: h.attrs.withAttrs(attrs), batch: h.batch}
+}
+
+func (h *LokiHandler) WithGroup(name string) slog.Handler {
+	return &LokiHandler{opts: h.opts, labels: h.labels, attrs: h.attrs.withGroup(name), batch: h.batch}
+}
+
+// Flush sends all buffered records.
+func (h *LokiHandler) Flush() error {
+	return h.batch.flush()
+}
+
+// Close sends all buffered records and stops the background goroutine.
+func (h *LokiHandler) Close() error {
+	return h.batch.close()
+}
+
+// appendLokiStream appends labels as JSON object with sorted keys to b.
+func appendLokiStream(b []byte, labels map[string]string) []byte {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	b = append(b, '{')
+	for i, name := range names {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, name)
+		b = append(b, ':')
+		b = appendJSONString(b, labels[name])
+	}
+	return append(b, '}')
+}
+
+// appendLokiPush appends the body of a push request to b. Entries are grouped by stream
+// in the order the streams first appear.
+func appendLokiPush(b []byte, entries []lokiEntry) []byte {
+	var streams []string
+	values := map[string][]lokiEntry{}
+	for _, e := range entries {
+		if _, ok := values[e.stream]; !ok {
+			streams = append(streams, e.stream)
+		}
+		values[e.stream] = append(values[e.stream], e)
+	}
+
+	b = append(b, `{"streams":[`...)
+	for i, stream := range streams {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, `{"stream":`...)
+		b = append(b, stream...)
+		b = append(b, `,"values":[`...)
+		for j, e := range values[stream] {
+			if j > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, `["`...)
+			b = strconv.AppendInt(b, e.time, 10)
+			b = append(b, `",`...)
+			b = appendJSONString(b, e.line)
+			b = append(b, ']')
+		}
+		b = append(b, "]}"...)
+	}
+	return append(b, "]}"...)
+}
+
+// lokiLabelName returns name as valid label name. Invalid characters are replaced by "_".
+func lokiLabelName(name string) string {
+	name = lokiInvalidLabelChars.ReplaceAllLiteralString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package eslog
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+// lokiServer is a stand-in for the Loki push API. It answers the first requests with 429.
+type lokiServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	pushes     []lokiPush
+	rateLimits int
+}
+
+func newLokiServer(t *testing.T, rateLimits int) *lokiServer {
+	s := &lokiServer{rateLimits: rateLimits}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		assert.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.rateLimits > 0 {
+			s.rateLimits--
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			body = zr
+		}
+		var push lokiPush
+		assert.NoError(t, json.NewDecoder(body).Decode(&push))
+		s.pushes = append(s.pushes, push)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *lokiServer) Pushes() []lokiPush {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pushes
+}
+
+func TestLokiHandler(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		srv := newLokiServer(t, 1)
+		h := NewLokiHandler(LokiOptions{
+			URL:          srv.URL + "/loki/api/v1/push",
+			Labels:       []string{"service", "level", "req.method"},
+			StaticLabels: map[string]string{"job": "test"},
+			TenantID:     "tenant",
+			Compress:     compress,
+			Batch:        BatchOptions{MinBackoff: time.Millisecond},
+		})
+
+		ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		api := h.WithAttrs([]slog.Attr{slog.String("service", "api")})
+		for _, level := range []slog.Level{slog.LevelInfo, slog.LevelError, slog.LevelInfo} {
+			r := slog.NewRecord(ts, level, "request done", 0)
+			r.Add("status", 200, slog.Group("req", "method", "GET", "path", "/users"))
+			assert.NoError(t, api.Handle(t.Context(), r))
+		}
+		assert.NoError(t, h.Close())
+
+		pushes := srv.Pushes()
+		assert.Equal(t, 1, len(pushes))
+		streams := pushes[0].Streams
+		assert.Equal(t, 2, len(streams))
+		assert.Equal(t, map[string]string{"job": "test", "service": "api", "level": "info", "req_method": "GET"}, streams[0].Stream)
+		line := `msg="request done" status=200 req.path=/users`
+		assert.Equal(t, [][2]string{{"1767323045000000000", line}, {"1767323045000000000", line}}, streams[0].Values)
+		assert.Equal(t, "error", streams[1].Stream["level"])
+		assert.Equal(t, 1, len(streams[1].Values))
+	}
+}
+
+func TestLokiHandlerLevelInLine(t *testing.T) {
+	srv := newLokiServer(t, 0)
+	h := NewLokiHandler(LokiOptions{URL: srv.URL + "/loki/api/v1/push", TenantID: "tenant"})
+
+	assert.NoError(t, h.WithGroup("g").Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelWarn, "disk full", 0)))
+	assert.NoError(t, h.Flush())
+
+	streams := srv.Pushes()[0].Streams
+	assert.Equal(t, map[string]string{}, streams[0].Stream)
+	assert.Equal(t, `level=WARN msg="disk full"`, streams[0].Values[0][1])
+	assert.NoError(t, h.Close())
+}
+
+func TestLokiLabelName(t *testing.T) {
+	tests := map[string]string{
+		"service":      "service",
+		"http.method":  "http_method",
+		"k8s-pod name": "k8s_pod_name",
+		"1st":          "_1st",
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, lokiLabelName(name))
+	}
+}
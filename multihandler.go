@@ -0,0 +1,146 @@
+package eslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Sink describes one output of a Logger configured with Config.Sinks. Each sink has its own
+// level, format and writer. Print output is only written to sinks using TextFormat.
+type Sink struct {
+	// Writer receives the formatted records and the Print output.
+	Writer io.Writer
+	// Format is the format used to write records to Writer.
+	Format Format
+	// Level is the minimum level of the sink. If nil the level of the Logger is used.
+	Level slog.Leveler
+	// Handler can be used instead of Writer and Format to plug in any slog.Handler. Such a
+	// sink doesn't receive Print output.
+	Handler slog.Handler
+	// PriorityPrefix prefixes each record with its sd-daemon priority like "<3>", see
+	// Config.PriorityPrefix.
+	PriorityPrefix bool
+	// Async writes the records of the sink in the background using an AsyncHandler.
+	Async *AsyncOptions
+}
+
+// newSinkHandler creates the handler for sink. opts are the handler options of the Logger.
+func newSinkHandler(sink Sink, opts slog.HandlerOptions) slog.Handler {
+	h := newSyncSinkHandler(sink, opts)
+	if sink.Async != nil {
+		return NewAsyncHandler(h, *sink.Async)
+	}
+	return h
+}
+
+// newSyncSinkHandler creates the handler for sink without the AsyncHandler.
+func newSyncSinkHandler(sink Sink, opts slog.HandlerOptions) slog.Handler {
+	if sink.Handler != nil {
+		return &noPrintHandler{h: sink.Handler, level: sink.Level}
+	}
+	if sink.Level != nil {
+		opts.Level = sink.Level
+	}
+	h := newWriterHandler(sink.Format, sink.Writer, &opts, sink.PriorityPrefix)
+	if sink.Format != TextFormat {
+		return &noPrintHandler{h: h}
+	}
+	return &printAwareHandler{h: h, w: sink.Writer}
+}
+
+// multiHandler passes each record to all of its handlers which are enabled for the level of
+// the record. A failing handler doesn't stop the others.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+func (m *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes r to all enabled handlers. Errors of all handlers are joined.
+func (m *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m.handlers {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, safeHandle(ctx, h, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *multiHandler) Flush() error {
+	return flushHandlers(m.handlers)
+}
+
+func (m *multiHandler) Close() error {
+	return closeHandlers(m.handlers)
+}
+
+func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(m.handlers))
+	for _, h := range m.handlers {
+		handlers = append(handlers, h.WithAttrs(attrs))
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+func (m *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(m.handlers))
+	for _, h := range m.handlers {
+		handlers = append(handlers, h.WithGroup(name))
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// safeHandle calls h.Handle and turns a panic of h into an error.
+func safeHandle(ctx context.Context, h slog.Handler, r slog.Record) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("eslog: handler panicked: %v", rec)
+		}
+	}()
+	return h.Handle(ctx, r)
+}
+
+// noPrintHandler wraps a slog.Handler which should not receive records of LevelPrint. If
+// level is set, records below level are filtered, too.
+type noPrintHandler struct {
+	h     slog.Handler
+	level slog.Leveler
+}
+
+func (n *noPrintHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level == LevelPrint || (n.level != nil && level < n.level.Level()) {
+		return false
+	}
+	return n.h.Enabled(ctx, level)
+}
+
+func (n *noPrintHandler) Handle(ctx context.Context, r slog.Record) error {
+	return n.h.Handle(ctx, r)
+}
+
+func (n *noPrintHandler) Flush() error {
+	return flushHandler(n.h)
+}
+
+func (n *noPrintHandler) Close() error {
+	return closeHandler(n.h)
+}
+
+func (n *noPrintHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &noPrintHandler{h: n.h.WithAttrs(attrs), level: n.level}
+}
+
+func (n *noPrintHandler) WithGroup(name string) slog.Handler {
+	return &noPrintHandler{h: n.h.WithGroup(name), level: n.level}
+}
@@ -0,0 +1,99 @@
+package eslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+type panicHandler struct{}
+
+func (panicHandler) Enabled(context.Context, slog.Level) bool  { return true }
+func (panicHandler) Handle(context.Context, slog.Record) error { panic("broken sink") }
+func (p panicHandler) WithAttrs([]slog.Attr) slog.Handler      { return p }
+func (p panicHandler) WithGroup(string) slog.Handler           { return p }
+
+func TestSinksLevelAndFormat(t *testing.T) {
+	text := &bytes.Buffer{}
+	json := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{Sinks: []Sink{
+		{Writer: text, Format: TextFormat, Level: slog.LevelInfo},
+		{Writer: json, Format: JSONFormat, Level: slog.LevelDebug},
+	}})
+
+	l.Debug("debug message")
+	l.Info("info message", "key", "value")
+	l.Print("print message\n")
+
+	assert.NotContains(t, text.String(), "debug message")
+	assert.Contains(t, text.String(), "level=INFO msg=\"info message\" key=value")
+	assert.Contains(t, text.String(), "print message\n")
+
+	assert.Contains(t, json.String(), `"level":"DEBUG","msg":"debug message"`)
+	assert.Contains(t, json.String(), `"level":"INFO","msg":"info message","key":"value"`)
+	assert.NotContains(t, json.String(), "print message")
+}
+
+func TestSinksDefaultToLoggerLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(&Config{Sinks: []Sink{{Writer: buf}}})
+	logLevel.Set(slog.LevelWarn)
+	defer logLevel.Set(slog.LevelDebug)
+
+	l.Info("info message")
+	l.Warn("warn message")
+
+	assert.NotContains(t, buf.String(), "info message")
+	assert.Contains(t, buf.String(), "warn message")
+}
+
+func TestSinksIsolateFailures(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{Sinks: []Sink{
+		{Writer: failingWriter{}},
+		{Handler: panicHandler{}},
+		{Writer: buf},
+	}})
+
+	err := l.Handler().Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "still logged", 0))
+
+	assert.Contains(t, buf.String(), "still logged")
+	assert.Equal(t, true, errors.Is(err, errWrite))
+	assert.Contains(t, err.Error(), "handler panicked: broken sink")
+}
+
+func TestSinksWithAttrsAndGroup(t *testing.T) {
+	text := &bytes.Buffer{}
+	json := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{Sinks: []Sink{
+		{Writer: text, Format: TextFormat},
+		{Writer: json, Format: JSONFormat},
+	}})
+
+	l.With("service", "test").WithGroup("req").Info("message", "id", 1)
+
+	assert.Contains(t, text.String(), "service=test req.id=1")
+	assert.Contains(t, json.String(), `"service":"test","req":{"id":1}`)
+}
+
+func TestHandlerSinkDoesNotReceivePrint(t *testing.T) {
+	text := &bytes.Buffer{}
+	custom := &bytes.Buffer{}
+	l := New(&Config{Sinks: []Sink{
+		{Writer: text},
+		{Handler: slog.NewTextHandler(custom, nil)},
+	}})
+
+	l.Print("print message")
+
+	assert.Equal(t, "print message", text.String())
+	assert.Equal(t, "", custom.String())
+}
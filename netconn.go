@@ -0,0 +1,95 @@
+package eslog
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultNetTimeout is used to dial and write if no timeout is configured.
+const defaultNetTimeout = 5 * time.Second
+
+// reconnectingConn writes messages to a network connection. The connection is dialed on
+// first use and redialed after a failed write.
+type reconnectingConn struct {
+	network string
+	addr    string
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newReconnectingConn(network, addr string, timeout time.Duration) *reconnectingConn {
+	if timeout <= 0 {
+		timeout = defaultNetTimeout
+	}
+	return &reconnectingConn{network: network, addr: addr, timeout: timeout}
+}
+
+// connect dials the connection if it is not connected yet.
+func (c *reconnectingConn) connect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dial()
+}
+
+// write writes each of msgs in a separate Write call, e.g. one datagram per message. If
+// writing fails the connection is redialed and the messages are written once more.
+func (c *reconnectingConn) write(msgs ...[]byte) error {
+	return c.do(func(conn net.Conn) error {
+		for _, msg := range msgs {
+			if _, err := conn.Write(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// do calls fn with the connection and a write deadline set. If fn fails the connection is
+// redialed and fn is called once more.
+func (c *reconnectingConn) do(fn func(conn net.Conn) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+	for range 2 {
+		if err = c.dial(); err != nil {
+			return err
+		}
+		if err = c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err == nil {
+			if err = fn(c.conn); err == nil {
+				return nil
+			}
+		}
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	return err
+}
+
+func (c *reconnectingConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// dial connects if not connected yet. c.mu must be held.
+func (c *reconnectingConn) dial() error {
+	if c.conn != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout(c.network, c.addr, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	return nil
+}
@@ -0,0 +1,249 @@
+package eslog
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// defaultOTLPEndpoint is the logs endpoint of a local OpenTelemetry collector.
+const defaultOTLPEndpoint = "http://localhost:4318/v1/logs"
+
+// otlpScopeName is the instrumentation scope of the exported log records.
+const otlpScopeName = "github.com/steffakasid/eslog"
+
+// defaultHTTPTimeout is the timeout of the HTTP client of exporters.
+const defaultHTTPTimeout = 10 * time.Second
+
+// OTLPOptions configures an OTLPHandler.
+type OTLPOptions struct {
+	// Endpoint is the URL of the OTLP/HTTP logs endpoint. Defaults to
+	// "http://localhost:4318/v1/logs".
+	Endpoint string
+	// Headers are added to each request, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is sent as resource attribute service.name. Defaults to the name of the
+	// executable.
+	ServiceName string
+	// ServiceVersion is sent as resource attribute service.version if set.
+	ServiceVersion string
+	// ResourceAttributes are additional resource attributes like deployment.environment.
+	ResourceAttributes []slog.Attr
+	// Level is the minimum level of the handler. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+	// Batch configures batching and retries.
+	Batch BatchOptions
+	// Client is used to send the requests. Defaults to a client with a timeout of 10s.
+	Client *http.Client
+}
+
+// OTLPHandler exports records as OpenTelemetry log records using OTLP/HTTP with JSON
+// encoding. Records are sent in batches in the background, call Close before the program
+// exits. Attributes of groups are flattened with ".". The attributes TraceIDKey and
+// SpanIDKey are sent as trace context if they are hex encoded IDs.
+type OTLPHandler struct {
+	opts  *OTLPOptions
+	attrs attrState
+	batch *batcher[[]byte]
+}
+
+// NewOTLPHandler creates an OTLPHandler and starts its background goroutine.
+func NewOTLPHandler(opts OTLPOptions) *OTLPHandler {
+	if opts.Endpoint == "" {
+		opts.Endpoint = defaultOTLPEndpoint
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = filepath.Base(os.Args[0])
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	h := &OTLPHandler{opts: &opts}
+	prefix, suffix := h.envelope()
+	encode := func(records [][]byte) []byte {
+		body := append([]byte{}, prefix...)
+		for i, record := range records {
+			if i > 0 {
+				body = append(body, ',')
+			}
+			body = append(body, record...)
+		}
+		return append(body, suffix...)
+	}
+	h.batch = newBatcher(opts.Batch, encode, func(body []byte) error {
+		return postHTTP(opts.Client, opts.Endpoint, "application/json", opts.Headers, body, false)
+	})
+	return h
+}
+
+func (h *OTLPHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle adds r to the current batch.
+func (h *OTLPHandler) Handle(_ context.Context, r slog.Record) error {
+	record := h.appendRecord(nil, r)
+	return h.batch.add(record, len(record))
+}
+
+func (h *OTLPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &OTLPHandler{opts: h.opts, attrs: h.attrs.withAttrs(attrs), batch: h.batch}
+}
+
+func (h *OTLPHandler) WithGroup(name string) slog.Handler {
+	return &OTLPHandler{opts: h.opts, attrs: h.attrs.withGroup(name), batch: h.batch}
+}
+
+// Flush sends all buffered records.
+func (h *OTLPHandler) Flush() error {
+	return h.batch.flush()
+}
+
+// Close sends all buffered records and stops the background goroutine.
+func (h *OTLPHandler) Close() error {
+	return h.batch.close()
+}
+
+// envelope returns the JSON in front of and after the log records of a request.
+func (h *OTLPHandler) envelope() (prefix, suffix []byte) {
+	resource := []slog.Attr{slog.String("service.name", h.opts.ServiceName)}
+	if h.opts.ServiceVersion != "" {
+		resource = append(resource, slog.String("service.version", h.opts.ServiceVersion))
+	}
+	var fields []field
+	for _, a := range append(resource, h.opts.ResourceAttributes...) {
+		fields = appendField(fields, nil, a)
+	}
+
+	prefix = append(prefix, `{"resourceLogs":[{"resource":{"attributes":[`...)
+	prefix = appendOTLPAttributes(prefix, fields)
+	prefix = append(prefix, `]},"scopeLogs":[{"scope":{"name":"`+otlpScopeName+`"},"logRecords":[`...)
+	return prefix, []byte(`]}]}]}`)
+}
+
+// appendRecord appends r as OTLP log record to b.
+func (h *OTLPHandler) appendRecord(b []byte, r slog.Record) []byte {
+	b = append(b, `{"observedTimeUnixNano":"`...)
+	b = strconv.AppendInt(b, time.Now().UnixNano(), 10)
+	b = append(b, '"')
+	if !r.Time.IsZero() {
+		b = append(b, `,"timeUnixNano":"`...)
+		b = strconv.AppendInt(b, r.Time.UnixNano(), 10)
+		b = append(b, '"')
+	}
+	b = append(b, `,"severityNumber":`...)
+	b = strconv.AppendInt(b, int64(otlpSeverityNumber(r.Level)), 10)
+	b = append(b, `,"severityText":`...)
+	b = appendJSONString(b, levelName(r.Level))
+	b = append(b, `,"body":{"stringValue":`...)
+	b = appendJSONString(b, r.Message)
+	b = append(b, '}')
+
+	fields := h.attrs.recordFields(r)
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		fields = append(fields,
+			field{key: "code.file.path", value: slog.StringValue(frame.File)},
+			field{key: "code.line.number", value: slog.IntValue(frame.Line)},
+			field{key: "code.function.name", value: slog.StringValue(frame.Function)})
+	}
+	attrs := fields[:0:0]
+	for _, f := range fields {
+		switch {
+		case len(f.groups) == 0 && f.key == TraceIDKey && isHexID(f.value, 16):
+			b = append(b, `,"traceId":`...)
+			b = appendJSONString(b, f.value.String())
+		case len(f.groups) == 0 && f.key == SpanIDKey && isHexID(f.value, 8):
+			b = append(b, `,"spanId":`...)
+			b = appendJSONString(b, f.value.String())
+		default:
+			attrs = append(attrs, f)
+		}
+	}
+	if len(attrs) > 0 {
+		b = append(b, `,"attributes":[`...)
+		b = appendOTLPAttributes(b, attrs)
+		b = append(b, ']')
+	}
+	return append(b, '}')
+}
+
+// appendOTLPAttributes appends fields as OTLP key values separated by commas to b.
+func appendOTLPAttributes(b []byte, fields []field) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, `{"key":`...)
+		b = appendJSONString(b, f.name("."))
+		b = append(b, `,"value":`...)
+		b = appendOTLPValue(b, f.value)
+		b = append(b, '}')
+	}
+	return b
+}
+
+// appendOTLPValue appends v as OTLP AnyValue to b. 64 bit integers are encoded as strings
+// like protobuf's JSON mapping requires.
+func appendOTLPValue(b []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindBool:
+		b = append(b, `{"boolValue":`...)
+		b = strconv.AppendBool(b, v.Bool())
+		return append(b, '}')
+	case slog.KindInt64:
+		b = append(b, `{"intValue":"`...)
+		b = strconv.AppendInt(b, v.Int64(), 10)
+		return append(b, '"', '}')
+	case slog.KindUint64:
+		if v.Uint64() <= math.MaxInt64 {
+			b = append(b, `{"intValue":"`...)
+			b = strconv.AppendUint(b, v.Uint64(), 10)
+			return append(b, '"', '}')
+		}
+	case slog.KindFloat64:
+		if f := v.Float64(); !math.IsInf(f, 0) && !math.IsNaN(f) {
+			b = append(b, `{"doubleValue":`...)
+			b = strconv.AppendFloat(b, f, 'g', -1, 64)
+			return append(b, '}')
+		}
+	}
+	b = append(b, `{"stringValue":`...)
+	b = appendJSONString(b, valueString(v))
+	return append(b, '}')
+}
+
+// otlpSeverityNumber maps level to an OpenTelemetry severity number. The slog levels are
+// 4 apart like the severity ranges, so LevelTrace maps to TRACE, LevelDebug to DEBUG and
+// LevelFatal to FATAL.
+func otlpSeverityNumber(level slog.Level) int {
+	return min(max(int(level)+9, 1), 24)
+}
+
+// isHexID reports whether v is a hex encoded ID of n bytes which isn't all zeros.
+func isHexID(v slog.Value, n int) bool {
+	if v.Kind() != slog.KindString {
+		return false
+	}
+	id, err := hex.DecodeString(v.String())
+	if err != nil || len(id) != n {
+		return false
+	}
+	for _, c := range id {
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package eslog
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// otlpCollector is a stand-in for an OpenTelemetry collector which records the requests.
+type otlpCollector struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []map[string]any
+	failures int
+}
+
+func newOTLPCollector(t *testing.T, failures int) *otlpCollector {
+	c := &otlpCollector{failures: failures}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.failures > 0 {
+			c.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var req map[string]any
+		assert.NoError(t, json.Unmarshal(body, &req))
+		c.requests = append(c.requests, req)
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *otlpCollector) Requests() []map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests
+}
+
+// otlpPath returns the value at path in the decoded JSON v.
+func otlpPath(v any, path ...any) any {
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			v = v.(map[string]any)[p]
+		case int:
+			v = v.([]any)[p]
+		}
+	}
+	return v
+}
+
+func TestOTLPHandler(t *testing.T) {
+	collector := newOTLPCollector(t, 2)
+	h := NewOTLPHandler(OTLPOptions{
+		Endpoint:           collector.URL + "/v1/logs",
+		ServiceName:        "api",
+		ServiceVersion:     "1.2.3",
+		ResourceAttributes: []slog.Attr{slog.String("deployment.environment", "test")},
+		Level:              LevelTrace,
+		Batch:              BatchOptions{MinBackoff: time.Millisecond},
+	})
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := slog.NewRecord(ts, LevelFatal, "request failed", 0)
+	r.Add("status", 500, "ratio", 0.5, "ok", false, slog.Group("user", "id", uint64(42)))
+	handler := h.WithAttrs([]slog.Attr{
+		slog.String("component", "db"),
+		slog.String(TraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736"),
+		slog.String(SpanIDKey, "00f067aa0ba902b7"),
+	}).WithGroup("http")
+	assert.NoError(t, handler.Handle(t.Context(), r))
+	assert.NoError(t, h.Handle(t.Context(), slog.NewRecord(ts, LevelTrace, "trace", 0)))
+	assert.NoError(t, h.Close())
+
+	requests := collector.Requests()
+	assert.Equal(t, 1, len(requests))
+	resourceLogs := otlpPath(requests[0], "resourceLogs", 0)
+	expectedResource := []any{
+		map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "api"}},
+		map[string]any{"key": "service.version", "value": map[string]any{"stringValue": "1.2.3"}},
+		map[string]any{"key": "deployment.environment", "value": map[string]any{"stringValue": "test"}},
+	}
+	assert.Equal(t, expectedResource, otlpPath(resourceLogs, "resource", "attributes"))
+	assert.Equal(t, any(otlpScopeName), otlpPath(resourceLogs, "scopeLogs", 0, "scope", "name"))
+
+	records := otlpPath(resourceLogs, "scopeLogs", 0, "logRecords").([]any)
+	assert.Equal(t, 2, len(records))
+	record := records[0].(map[string]any)
+	assert.Equal(t, true, record["observedTimeUnixNano"] != nil)
+	delete(record, "observedTimeUnixNano")
+	expectedRecord := map[string]any{
+		"timeUnixNano":   "1767323045000000000",
+		"severityNumber": float64(21),
+		"severityText":   "FATAL",
+		"body":           map[string]any{"stringValue": "request failed"},
+		"traceId":        "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":         "00f067aa0ba902b7",
+		"attributes": []any{
+			map[string]any{"key": "component", "value": map[string]any{"stringValue": "db"}},
+			map[string]any{"key": "http.status", "value": map[string]any{"intValue": "500"}},
+			map[string]any{"key": "http.ratio", "value": map[string]any{"doubleValue": 0.5}},
+			map[string]any{"key": "http.ok", "value": map[string]any{"boolValue": false}},
+			map[string]any{"key": "http.user.id", "value": map[string]any{"intValue": "42"}},
+		},
+	}
+	assert.Equal(t, expectedRecord, record)
+	assert.Equal(t, any(float64(1)), otlpPath(records[1], "severityNumber"))
+}
+
+func TestOTLPSeverityNumber(t *testing.T) {
+	tests := map[slog.Level]int{
+		LevelTrace - 4:     1,
+		LevelTrace:         1,
+		slog.LevelDebug:    5,
+		slog.LevelInfo:     9,
+		slog.LevelInfo + 2: 11,
+		slog.LevelWarn:     13,
+		slog.LevelError:    17,
+		LevelFatal:         21,
+		LevelFatal + 8:     24,
+	}
+	for level, expected := range tests {
+		assert.Equal(t, expected, otlpSeverityNumber(level))
+	}
+}
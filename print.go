@@ -36,21 +36,104 @@ func Println(args ...any) {
 	Logger.Println(args...)
 }
 
+// PrintV logs like Print but only if the verbosity set with SetVerbosity (or the log level
+// respectively) is at least verbosity. See [VerbosityLevel] for the mapping of verbosity to
+// levels. A verbosity of 0 or less behaves like Print.
+func PrintV(verbosity int, args ...any) {
+	Logger.PrintV(verbosity, args...)
+}
+
+// PrintVf is the formatting counterpart of PrintV.
+func PrintVf(verbosity int, format string, args ...any) {
+	Logger.PrintVf(verbosity, format, args...)
+}
+
+// PrintVln is the fmt.Sprintln counterpart of PrintV.
+func PrintVln(verbosity int, args ...any) {
+	Logger.PrintVln(verbosity, args...)
+}
+
+// Fprint is like Print but returns the write error to the caller instead of passing it to
+// the print error handler of the Logger.
+func Fprint(args ...any) error {
+	return Logger.Fprint(args...)
+}
+
+// Fprintf is like Printf but returns the write error to the caller instead of passing it to
+// the print error handler of the Logger.
+func Fprintf(format string, args ...any) error {
+	return Logger.Fprintf(format, args...)
+}
+
+// Fprintln is like Println but returns the write error to the caller instead of passing it
+// to the print error handler of the Logger.
+func Fprintln(args ...any) error {
+	return Logger.Fprintln(args...)
+}
+
 func (l eSlogLogger) Print(args ...any) {
-	err := l.Handler().Handle(context.Background(), slog.Record{
+	l.handlePrintError(l.Fprint(args...))
+}
+
+func (l eSlogLogger) Printf(format string, args ...any) {
+	l.handlePrintError(l.Fprintf(format, args...))
+}
+
+func (l eSlogLogger) Println(args ...any) {
+	l.handlePrintError(l.Fprintln(args...))
+}
+
+func (l eSlogLogger) Fprint(args ...any) error {
+	if !l.Handler().Enabled(context.Background(), LevelPrint) {
+		return nil
+	}
+	return l.Handler().Handle(context.Background(), slog.Record{
 		Level:   LevelPrint,
 		Message: fmt.Sprint(args...),
 	})
+}
+
+func (l eSlogLogger) Fprintf(format string, args ...any) error {
+	return l.Fprint(fmt.Sprintf(format, args...))
+}
+
+func (l eSlogLogger) Fprintln(args ...any) error {
+	args = append(args, "\n")
+	return l.Fprint(args...)
+}
+
+// handlePrintError passes a non nil err to the print error handler of the Logger.
+func (l eSlogLogger) handlePrintError(err error) {
 	if err != nil {
-		panic(err)
+		l.onPrintError(err)
 	}
 }
 
-func (l eSlogLogger) Printf(format string, args ...any) {
-	l.Print(fmt.Sprintf(format, args...))
+func (l eSlogLogger) PrintV(verbosity int, args ...any) {
+	if l.verbosityEnabled(verbosity) {
+		l.Print(args...)
+	}
 }
 
-func (l eSlogLogger) Println(args ...any) {
-	args = append(args, "\n")
-	l.Print(args...)
+func (l eSlogLogger) PrintVf(verbosity int, format string, args ...any) {
+	if l.verbosityEnabled(verbosity) {
+		l.Printf(format, args...)
+	}
+}
+
+func (l eSlogLogger) PrintVln(verbosity int, args ...any) {
+	if l.verbosityEnabled(verbosity) {
+		l.Println(args...)
+	}
+}
+
+// verbosityEnabled reports whether the level belonging to verbosity is enabled. A verbosity
+// of 0 or less is always enabled.
+func (l eSlogLogger) verbosityEnabled(verbosity int) bool {
+	if verbosity <= 0 {
+		return true
+	}
+	// The level of the Logger is checked directly, as handlers like the one of
+	// Config.FingersCrossed or a sink with a lower level are enabled for lower levels.
+	return VerbosityLevel(verbosity) >= logLevel.Level()
 }
@@ -0,0 +1,40 @@
+package eslog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// exit is used to terminate the program. It can be replaced in tests.
+var exit = os.Exit
+
+// PrintErrorOnce returns a print error handler which writes the first error it receives to
+// w and ignores all following errors. PrintErrorOnce(os.Stderr) is used if no
+// Config.OnPrintError is set.
+func PrintErrorOnce(w io.Writer) func(error) {
+	once := sync.Once{}
+	return func(err error) {
+		once.Do(func() {
+			_, _ = fmt.Fprintf(w, "eslog: print failed: %s\n", err)
+		})
+	}
+}
+
+// ExitOnBrokenPipe returns a print error handler which exits the program quietly with exit
+// code 0 if err is a broken pipe (EPIPE), e.g. if the output is piped to `head` which
+// already terminated. All other errors are passed to next if next is not nil.
+func ExitOnBrokenPipe(next func(error)) func(error) {
+	return func(err error) {
+		if errors.Is(err, syscall.EPIPE) {
+			exit(0)
+			return
+		}
+		if next != nil {
+			next(err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package eslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+var errWrite = errors.New("write failed")
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestPrintErrorHandler(t *testing.T) {
+	var errs []error
+	l := New(&Config{
+		out:          failingWriter{},
+		OnPrintError: func(err error) { errs = append(errs, err) },
+	})
+
+	l.Print("print")
+	l.Printf("printf %d", 1)
+	l.Println("println")
+
+	assert.Equal(t, 3, len(errs))
+	for _, err := range errs {
+		assert.Equal(t, true, errors.Is(err, errWrite))
+	}
+}
+
+func TestFprint(t *testing.T) {
+	l := New(&Config{out: failingWriter{}})
+
+	assert.Equal(t, true, errors.Is(l.Fprint("print"), errWrite))
+	assert.Equal(t, true, errors.Is(l.Fprintf("printf %d", 1), errWrite))
+	assert.Equal(t, true, errors.Is(l.Fprintln("println"), errWrite))
+
+	buf := &bytes.Buffer{}
+	l = New(&Config{out: buf})
+	assert.NoError(t, l.Fprintln("ok"))
+	assert.Equal(t, "ok\n", buf.String())
+}
+
+func TestPrintErrorOnce(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := PrintErrorOnce(buf)
+
+	handler(errors.New("first"))
+	handler(errors.New("second"))
+
+	assert.Equal(t, "eslog: print failed: first\n", buf.String())
+}
+
+func TestExitOnBrokenPipe(t *testing.T) {
+	exitCode := -1
+	exit = func(code int) { exitCode = code }
+	defer func() { exit = os.Exit }()
+
+	var passed []error
+	handler := ExitOnBrokenPipe(func(err error) { passed = append(passed, err) })
+
+	handler(errWrite)
+	assert.Equal(t, -1, exitCode)
+	assert.Equal(t, []error{errWrite}, passed)
+
+	handler(fmt.Errorf("write /dev/stdout: %w", syscall.EPIPE))
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, 1, len(passed))
+}
+
+func TestPrintToClosedPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	defer func() { _ = w.Close() }()
+
+	exitCode := -1
+	exit = func(code int) { exitCode = code }
+	defer func() { exit = os.Exit }()
+
+	l := New(&Config{out: w, OnPrintError: ExitOnBrokenPipe(nil)})
+	l.Println("nobody reads this")
+
+	assert.Equal(t, 0, exitCode)
+}
@@ -0,0 +1,61 @@
+package eslog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// newWriterHandler creates the handler writing format to w. If priorityPrefix is set each
+// record is prefixed with its sd-daemon priority.
+func newWriterHandler(format Format, w io.Writer, opts *slog.HandlerOptions, priorityPrefix bool) slog.Handler {
+	if !priorityPrefix {
+		return newFormatHandler(format, w, opts)
+	}
+	pw := &priorityWriter{w: w}
+	return &priorityPrefixHandler{h: newFormatHandler(format, pw, opts), w: pw}
+}
+
+// priorityPrefixHandler prefixes each record written by the wrapped format handler with
+// the syslog severity of its level in the "<N>" format of sd-daemon. systemd uses the
+// prefix as priority of lines written to stdout or stderr.
+type priorityPrefixHandler struct {
+	h slog.Handler
+	w *priorityWriter
+}
+
+func (p *priorityPrefixHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return p.h.Enabled(ctx, level)
+}
+
+func (p *priorityPrefixHandler) Handle(ctx context.Context, r slog.Record) error {
+	p.w.mu.Lock()
+	defer p.w.mu.Unlock()
+	p.w.prefix = fmt.Appendf(p.w.prefix[:0], "<%d>", syslogSeverity(r.Level))
+	return p.h.Handle(ctx, r)
+}
+
+func (p *priorityPrefixHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &priorityPrefixHandler{h: p.h.WithAttrs(attrs), w: p.w}
+}
+
+func (p *priorityPrefixHandler) WithGroup(name string) slog.Handler {
+	return &priorityPrefixHandler{h: p.h.WithGroup(name), w: p.w}
+}
+
+// priorityWriter writes prefix in front of each write. The format handlers write each
+// record with a single Write call.
+type priorityWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix []byte
+}
+
+func (p *priorityWriter) Write(b []byte) (int, error) {
+	line := make([]byte, 0, len(p.prefix)+len(b))
+	line = append(append(line, p.prefix...), b...)
+	n, err := p.w.Write(line)
+	return max(0, n-len(p.prefix)), err
+}
@@ -0,0 +1,43 @@
+package eslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestPriorityPrefix(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(LevelTrace)
+	l := New(&Config{PriorityPrefix: true, Format: JSONFormat, out: buf})
+
+	l.Log(context.Background(), LevelTrace, "trace")
+	l.With("a", "b").Info("info")
+	l.Warn("warn")
+	l.Error("error")
+	l.Print("print\n")
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Equal(t, 5, len(lines))
+	for i, prefix := range []string{"<7>{", "<6>{", "<4>{", "<3>{", "print"} {
+		assert.Equal(t, true, bytes.HasPrefix(lines[i], []byte(prefix)))
+	}
+}
+
+func TestPriorityPrefixSink(t *testing.T) {
+	prefixed, plain := &bytes.Buffer{}, &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{Sinks: []Sink{
+		{Writer: prefixed, PriorityPrefix: true},
+		{Writer: plain},
+	}})
+
+	l.Error("failed")
+
+	assert.Contains(t, prefixed.String(), "<3>time=")
+	assert.Contains(t, plain.String(), "time=")
+	assert.NotContains(t, plain.String(), "<3>")
+}
@@ -0,0 +1,130 @@
+package eslog
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// secretMask is the replacement of redacted values.
+const secretMask = "***"
+
+// Secret is a string which is never logged. It renders as "***" in records, in formatted
+// messages (e.g. Infof("%s", secret)) and in Print output.
+type Secret string
+
+// LogValue implements slog.LogValuer.
+func (Secret) LogValue() slog.Value {
+	return slog.StringValue(secretMask)
+}
+
+// String implements fmt.Stringer.
+func (Secret) String() string {
+	return secretMask
+}
+
+// GoString implements fmt.GoStringer, so %#v doesn't reveal the secret either.
+func (Secret) GoString() string {
+	return secretMask
+}
+
+// RedactOptions configures the redaction of sensitive data with Config.Redact.
+type RedactOptions struct {
+	// Keys are attribute keys whose values are masked. Keys are compared
+	// case-insensitively and also match attributes inside groups.
+	Keys []string
+	// Patterns are applied to string values, error messages, the message of records and
+	// Print output. All matches are masked.
+	Patterns []*regexp.Regexp
+	// Mask replaces redacted values. Defaults to "***".
+	Mask string
+}
+
+// redactHandler masks sensitive attributes and values before passing records to the
+// wrapped handler.
+type redactHandler struct {
+	h    slog.Handler
+	opts *RedactOptions
+	keys map[string]struct{}
+}
+
+func newRedactHandler(h slog.Handler, opts RedactOptions) *redactHandler {
+	if opts.Mask == "" {
+		opts.Mask = secretMask
+	}
+	keys := make(map[string]struct{}, len(opts.Keys))
+	for _, key := range opts.Keys {
+		keys[strings.ToLower(key)] = struct{}{}
+	}
+	return &redactHandler{h: h, opts: &opts, keys: keys}
+}
+
+func (rh *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return rh.h.Enabled(ctx, level)
+}
+
+func (rh *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, rh.redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(rh.redactAttr(a))
+		return true
+	})
+	return rh.h.Handle(ctx, redacted)
+}
+
+func (rh *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, rh.redactAttr(a))
+	}
+	return &redactHandler{h: rh.h.WithAttrs(redacted), opts: rh.opts, keys: rh.keys}
+}
+
+func (rh *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{h: rh.h.WithGroup(name), opts: rh.opts, keys: rh.keys}
+}
+
+func (rh *redactHandler) Flush() error {
+	return flushHandler(rh.h)
+}
+
+func (rh *redactHandler) Close() error {
+	return closeHandler(rh.h)
+}
+
+// redactAttr masks a if its key is sensitive. Otherwise groups are redacted recursively
+// and the patterns are applied to strings and errors.
+func (rh *redactHandler) redactAttr(a slog.Attr) slog.Attr {
+	if _, ok := rh.keys[strings.ToLower(a.Key)]; ok {
+		return slog.String(a.Key, rh.opts.Mask)
+	}
+
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			redacted = append(redacted, rh.redactAttr(ga))
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindString:
+		a.Value = slog.StringValue(rh.redactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			if msg := rh.redactString(err.Error()); msg != err.Error() {
+				a.Value = slog.StringValue(msg)
+			}
+		}
+	}
+	return a
+}
+
+// redactString masks all matches of the patterns in s.
+func (rh *redactHandler) redactString(s string) string {
+	for _, p := range rh.opts.Patterns {
+		s = p.ReplaceAllLiteralString(s, rh.opts.Mask)
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package eslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// cardNumber matches credit card numbers like 4111-1111-1111-1111.
+var cardNumber = regexp.MustCompile(`\b(?:\d{4}[- ]?){3}\d{4}\b`)
+
+func newRedactLogger(format Format) (*eSlogLogger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{
+		Format: format,
+		Redact: &RedactOptions{
+			Keys:     []string{"password", "Token"},
+			Patterns: []*regexp.Regexp{cardNumber},
+		},
+		out: buf,
+	})
+	return l, buf
+}
+
+func TestRedactKeys(t *testing.T) {
+	l, buf := newRedactLogger(TextFormat)
+
+	l.Info("login", "user", "bob", "PASSWORD", "hunter2", slog.Group("auth", "token", "abc", "kind", "bearer"))
+	l.With("Password", "hunter2").WithGroup("req").Info("request", slog.Group("headers", "TOKEN", "abc"))
+
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "abc")
+	assert.Contains(t, buf.String(), "user=bob PASSWORD=*** auth.token=*** auth.kind=bearer")
+	assert.Contains(t, buf.String(), "Password=*** req.headers.TOKEN=***")
+}
+
+func TestRedactPatterns(t *testing.T) {
+	l, buf := newRedactLogger(JSONFormat)
+
+	l.Infof("charging card %s", "4111-1111-1111-1111")
+	l.Info("payment", "card", "4111 1111 1111 1111", "error", errors.New("card 4111111111111111 declined"), "amount", 42)
+	l.Print("card 4111-1111-1111-1111\n")
+
+	assert.NotContains(t, buf.String(), "4111")
+	assert.Contains(t, buf.String(), `"msg":"charging card ***"`)
+	assert.Contains(t, buf.String(), `"card":"***","error":"card *** declined","amount":42`)
+	assert.Contains(t, buf.String(), "card ***\n")
+}
+
+func TestRedactCustomMask(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(&Config{Redact: &RedactOptions{Keys: []string{"password"}, Mask: "[REDACTED]"}, out: buf})
+
+	l.Error("login failed", "password", "hunter2")
+
+	assert.Contains(t, buf.String(), "password=[REDACTED]")
+}
+
+func TestSecret(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{out: buf})
+	secret := Secret("hunter2")
+
+	l.Info("login", "password", secret)
+	l.Infof("formatted %s %v %#v", secret, secret, secret)
+	l.Print(secret, "\n")
+
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Contains(t, buf.String(), "password=***")
+	assert.Contains(t, buf.String(), `msg="formatted *** *** ***"`)
+	assert.Contains(t, buf.String(), "***\n")
+	assert.Equal(t, "***", fmt.Sprint(secret))
+}
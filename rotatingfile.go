@@ -0,0 +1,316 @@
+package eslog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat is used in the names of rotated files. It sorts lexically and contains
+// no characters which are invalid in file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// compressSuffix is appended to compressed rotated files.
+const compressSuffix = ".gz"
+
+// RotateOptions configures a RotatingFile. The zero value never rotates.
+type RotateOptions struct {
+	// MaxSize rotates the file before a write would make it exceed MaxSize bytes. 0
+	// disables size based rotation.
+	MaxSize int64
+	// Daily rotates the file on the first write of a new day.
+	Daily bool
+	// Compress compresses rotated files with gzip.
+	Compress bool
+	// MaxBackups is the number of rotated files to keep. 0 keeps all.
+	MaxBackups int
+	// MaxAge removes rotated files which are older than MaxAge. 0 keeps all.
+	MaxAge time.Duration
+	// ReopenOnSIGHUP reopens the file when the process receives SIGHUP. This is needed if
+	// the file is rotated by an external tool like logrotate.
+	ReopenOnSIGHUP bool
+}
+
+// RotatingFile is an io.WriteCloser writing to a file which is rotated by size and/or
+// daily. Rotated files are renamed to <name>-<timestamp><ext> and optionally compressed.
+// It can be used as writer of a Sink.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	opts     RotateOptions
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+
+	// millMu serializes compression and removal of rotated files.
+	millMu sync.Mutex
+	wg     sync.WaitGroup
+
+	sighup chan os.Signal
+	done   chan struct{}
+}
+
+// NewRotatingFile opens or creates the file at path for appending.
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path: path,
+		opts: opts,
+		now:  time.Now,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if opts.ReopenOnSIGHUP {
+		f.sighup = make(chan os.Signal, 1)
+		f.done = make(chan struct{})
+		signal.Notify(f.sighup, syscall.SIGHUP)
+		go f.handleSIGHUP()
+	}
+	return f, nil
+}
+
+// Write writes p to the file. The file is rotated first if needed.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.needsRotation(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file immediately.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Reopen closes and reopens the file. Use it after the file was moved by another program.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close closes the file and waits until rotated files are compressed and cleaned up.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	if f.sighup != nil {
+		signal.Stop(f.sighup)
+		close(f.done)
+	}
+	err := f.file.Close()
+	f.file = nil
+	f.wg.Wait()
+	return err
+}
+
+// handleSIGHUP reopens the file whenever SIGHUP is received until the file is closed.
+func (f *RotatingFile) handleSIGHUP() {
+	for {
+		select {
+		case <-f.sighup:
+			if err := f.Reopen(); err != nil && !errors.Is(err, os.ErrClosed) {
+				_, _ = fmt.Fprintf(os.Stderr, "eslog: reopen %s failed: %s\n", f.path, err)
+			}
+		case <-f.done:
+			return
+		}
+	}
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (f *RotatingFile) needsRotation(n int64) bool {
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	if f.opts.Daily && f.size > 0 {
+		y1, m1, d1 := f.openedAt.Date()
+		y2, m2, d2 := f.now().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	return false
+}
+
+// rotate renames the current file, opens a new one and starts compression and cleanup of
+// rotated files in the background. f.mu must be held.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	backup := f.backupName(f.now())
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.mill(backup)
+	}()
+	return nil
+}
+
+// mill compresses backup if configured and removes rotated files exceeding MaxBackups or
+// MaxAge. Errors are reported to os.Stderr as there is no caller to return them to.
+func (f *RotatingFile) mill(backup string) {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	if f.opts.Compress {
+		if err := compressFile(backup); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "eslog: compress %s failed: %s\n", backup, err)
+		}
+	}
+	if err := f.removeOldBackups(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "eslog: remove old backups of %s failed: %s\n", f.path, err)
+	}
+}
+
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	return filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+}
+
+// nameParts splits the path into the directory, the prefix of rotated files and the
+// extension.
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir, base := filepath.Split(f.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// backupFile is a rotated file with the time parsed from its name.
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups returns the rotated files of f, newest first.
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := f.nameParts()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := f.now().Location()
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)
+		// Names are written in the time zone of the clock, see backupName.
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimPrefix(ts, prefix), loc)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: t})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+	return backups, nil
+}
+
+func (f *RotatingFile) removeOldBackups() error {
+	if f.opts.MaxBackups == 0 && f.opts.MaxAge == 0 {
+		return nil
+	}
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	cutoff := f.now().Add(-f.opts.MaxAge)
+	for i, backup := range backups {
+		tooMany := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		tooOld := f.opts.MaxAge > 0 && backup.time.Before(cutoff)
+		if tooMany || tooOld {
+			errs = append(errs, os.Remove(backup.path))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compressFile writes path gzip compressed to path.gz and removes path.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	err = errors.Join(err, gz.Close(), dst.Close())
+	if err != nil {
+		_ = os.Remove(path + compressSuffix)
+		return err
+	}
+	return os.Remove(path)
+}
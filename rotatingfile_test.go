@@ -0,0 +1,188 @@
+package eslog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// fakeClock returns a clock starting at start which can be advanced by tests.
+func fakeClock(start time.Time) (now func() time.Time, advance func(time.Duration)) {
+	current := start
+	return func() time.Time { return current }, func(d time.Duration) { current = current.Add(d) }
+}
+
+func newTestRotatingFile(t *testing.T, opts RotateOptions) (*RotatingFile, string, func(time.Duration)) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewRotatingFile(path, opts)
+	assert.NoError(t, err)
+	// A fixed zone other than UTC keeps the names independent of TZ and checks that they
+	// are parsed in the zone they were written in.
+	now, advance := fakeClock(time.Date(2026, 1, 2, 10, 0, 0, 0, time.FixedZone("EST", -5*60*60)))
+	f.mu.Lock()
+	f.now = now
+	f.openedAt = now()
+	f.mu.Unlock()
+	return f, path, advance
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestRotatingFileMaxSize(t *testing.T) {
+	f, path, advance := newTestRotatingFile(t, RotateOptions{MaxSize: 10})
+
+	_, err := f.Write([]byte("12345\n"))
+	assert.NoError(t, err)
+	advance(time.Second)
+	_, err = f.Write([]byte("67890\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "67890\n", readFile(t, path))
+	backup := filepath.Join(filepath.Dir(path), "app-2026-01-02T10-00-01.000.log")
+	assert.Equal(t, "12345\n", readFile(t, backup))
+}
+
+func TestRotatingFileDaily(t *testing.T) {
+	f, path, advance := newTestRotatingFile(t, RotateOptions{Daily: true})
+
+	_, err := f.Write([]byte("day one\n"))
+	assert.NoError(t, err)
+	advance(time.Hour)
+	_, err = f.Write([]byte("still day one\n"))
+	assert.NoError(t, err)
+	advance(24 * time.Hour)
+	_, err = f.Write([]byte("day two\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "day two\n", readFile(t, path))
+	backup := filepath.Join(filepath.Dir(path), "app-2026-01-03T11-00-00.000.log")
+	assert.Equal(t, "day one\nstill day one\n", readFile(t, backup))
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	f, path, _ := newTestRotatingFile(t, RotateOptions{Compress: true})
+
+	_, err := f.Write([]byte("compress me\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Rotate())
+	assert.NoError(t, f.Close())
+
+	backup := filepath.Join(filepath.Dir(path), "app-2026-01-02T10-00-00.000.log")
+	_, err = os.Stat(backup)
+	assert.Equal(t, true, os.IsNotExist(err))
+
+	gzFile, err := os.Open(backup + ".gz")
+	assert.NoError(t, err)
+	defer func() { _ = gzFile.Close() }()
+	gz, err := gzip.NewReader(gzFile)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	assert.NoError(t, err)
+	assert.Equal(t, "compress me\n", string(data))
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     RotateOptions
+		expected []string
+	}{
+		{"max backups", RotateOptions{MaxBackups: 3}, []string{"app-2026-01-02T10-00-03.000.log", "app-2026-01-02T10-00-04.000.log"}},
+		{"max age", RotateOptions{MaxAge: 61500 * time.Millisecond}, []string{"app-2026-01-02T10-00-03.000.log", "app-2026-01-02T10-00-04.000.log"}},
+		{"max backups compressed", RotateOptions{MaxBackups: 2, Compress: true}, []string{"app-2026-01-02T10-00-04.000.log.gz"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, path, advance := newTestRotatingFile(t, tt.opts)
+			for range 4 {
+				advance(time.Second)
+				_, err := f.Write([]byte("line\n"))
+				assert.NoError(t, err)
+				assert.NoError(t, f.Rotate())
+				// Wait for compression and cleanup to keep the result deterministic.
+				f.wg.Wait()
+			}
+			// The last rotation at 10:01:04 triggers the cleanup and is ignored below.
+			advance(time.Minute)
+			assert.NoError(t, f.Rotate())
+			assert.NoError(t, f.Close())
+
+			entries, err := os.ReadDir(filepath.Dir(path))
+			assert.NoError(t, err)
+			var backups []string
+			for _, entry := range entries {
+				if entry.Name() != "app.log" && !strings.Contains(entry.Name(), "10-01-04") {
+					backups = append(backups, entry.Name())
+				}
+			}
+			assert.Equal(t, tt.expected, backups)
+		})
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	f, path, _ := newTestRotatingFile(t, RotateOptions{})
+
+	_, err := f.Write([]byte("before\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, f.Reopen())
+	_, err = f.Write([]byte("after\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "before\n", readFile(t, path+".1"))
+	assert.Equal(t, "after\n", readFile(t, path))
+
+	_, err = f.Write([]byte("closed\n"))
+	assert.Equal(t, os.ErrClosed, err)
+}
+
+func TestRotatingFileSIGHUP(t *testing.T) {
+	f, path, _ := newTestRotatingFile(t, RotateOptions{ReopenOnSIGHUP: true})
+	defer func() { _ = f.Close() }()
+
+	assert.NoError(t, os.Rename(path, path+".1"))
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Skipf("sending SIGHUP not supported: %s", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("file was not reopened after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRotatingFileAsSink(t *testing.T) {
+	f, path, _ := newTestRotatingFile(t, RotateOptions{MaxSize: 1024})
+	l := New(&Config{Sinks: []Sink{{Writer: f, Format: JSONFormat}}})
+
+	l.Error("to file")
+	assert.NoError(t, f.Close())
+
+	assert.Contains(t, readFile(t, path), `"msg":"to file"`)
+}
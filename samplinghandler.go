@@ -0,0 +1,170 @@
+package eslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingOptions configures the sampling of records with Config.Sampling. Within each
+// interval the First records with the same level and message are logged, after that only
+// every Thereafter-th record. Records of LevelFatal (and above) and LevelPrint are never
+// sampled.
+type SamplingOptions struct {
+	// Interval is the length of a sampling interval. Defaults to one second.
+	Interval time.Duration
+	// First is the number of records per level and message which are logged in each
+	// interval before sampling starts.
+	First uint64
+	// Thereafter logs every Thereafter-th record after the First records. 0 drops all.
+	Thereafter uint64
+}
+
+// samplingKey identifies records which are sampled together.
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+// samplingCounter counts the records of a samplingKey in the current interval.
+type samplingCounter struct {
+	count      uint64
+	suppressed uint64
+}
+
+// samplingState is shared between a samplingHandler and the handlers derived from it.
+type samplingState struct {
+	opts SamplingOptions
+	now  func() time.Time
+	// report receives the summaries of suppressed records.
+	report slog.Handler
+
+	mu       sync.Mutex
+	interval time.Time
+	counters map[samplingKey]*samplingCounter
+
+	suppressed atomic.Uint64
+}
+
+// samplingHandler drops records according to SamplingOptions. At the end of an interval
+// the number of suppressed records is logged for each level and message as attribute
+// "suppressed".
+type samplingHandler struct {
+	h     slog.Handler
+	state *samplingState
+}
+
+func newSamplingHandler(h slog.Handler, opts SamplingOptions) *samplingHandler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	return &samplingHandler{h: h, state: &samplingState{
+		opts:     opts,
+		now:      time.Now,
+		report:   h,
+		counters: map[samplingKey]*samplingCounter{},
+	}}
+}
+
+func (s *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.h.Enabled(ctx, level)
+}
+
+func (s *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == LevelPrint || r.Level >= LevelFatal {
+		return s.h.Handle(ctx, r)
+	}
+	if !s.state.sample(ctx, samplingKey{level: r.Level, msg: r.Message}) {
+		return nil
+	}
+	return s.h.Handle(ctx, r)
+}
+
+func (s *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{h: s.h.WithAttrs(attrs), state: s.state}
+}
+
+func (s *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{h: s.h.WithGroup(name), state: s.state}
+}
+
+// suppressed returns the total number of records dropped by sampling.
+func (s *samplingHandler) suppressed() uint64 {
+	return s.state.suppressed.Load()
+}
+
+// Flush logs the summaries of the current interval and flushes the wrapped handler.
+func (s *samplingHandler) Flush() error {
+	s.state.mu.Lock()
+	summaries := s.state.reset(s.state.interval)
+	s.state.mu.Unlock()
+	s.state.logSummaries(context.Background(), summaries)
+	return flushHandler(s.h)
+}
+
+func (s *samplingHandler) Close() error {
+	if err := s.Flush(); err != nil {
+		return err
+	}
+	return closeHandler(s.h)
+}
+
+// sample reports whether a record with key should be logged.
+func (s *samplingState) sample(ctx context.Context, key samplingKey) bool {
+	s.mu.Lock()
+	var summaries map[samplingKey]uint64
+	interval := s.now().Truncate(s.opts.Interval)
+	if !interval.Equal(s.interval) {
+		summaries = s.reset(interval)
+	}
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &samplingCounter{}
+		s.counters[key] = c
+	}
+	c.count++
+	keep := c.count <= s.opts.First ||
+		(s.opts.Thereafter > 0 && (c.count-s.opts.First)%s.opts.Thereafter == 0)
+	if !keep {
+		c.suppressed++
+		s.suppressed.Add(1)
+	}
+	s.mu.Unlock()
+
+	s.logSummaries(ctx, summaries)
+	return keep
+}
+
+// reset starts a new interval and returns the suppressed counts of the previous one. s.mu
+// must be held.
+func (s *samplingState) reset(interval time.Time) map[samplingKey]uint64 {
+	summaries := map[samplingKey]uint64{}
+	for key, c := range s.counters {
+		if c.suppressed > 0 {
+			summaries[key] = c.suppressed
+		}
+	}
+	s.interval = interval
+	s.counters = map[samplingKey]*samplingCounter{}
+	return summaries
+}
+
+func (s *samplingState) logSummaries(ctx context.Context, summaries map[samplingKey]uint64) {
+	for key, suppressed := range summaries {
+		r := slog.NewRecord(s.now(), key.level, key.msg, 0)
+		r.AddAttrs(slog.Uint64("suppressed", suppressed))
+		_ = s.report.Handle(ctx, r)
+	}
+}
+
+// Suppressed returns the total number of records dropped by Config.Sampling. It returns 0
+// if sampling is not configured.
+func (l eSlogLogger) Suppressed() uint64 {
+	if l.sampling == nil {
+		return 0
+	}
+	return l.sampling.suppressed()
+}
@@ -0,0 +1,77 @@
+package eslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newSamplingHandler(rec, SamplingOptions{Interval: time.Second, First: 2, Thereafter: 3})
+	now, advance := fakeClock(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	h.state.now = now
+
+	for range 10 {
+		handleMessage(t, h, "hot")
+	}
+	handleMessage(t, h, "other")
+
+	// First 2, then every 3rd: records 1, 2, 5 and 8.
+	assert.Equal(t, []string{"hot", "hot", "hot", "hot", "other"}, rec.Messages())
+	assert.Equal(t, uint64(6), h.suppressed())
+
+	advance(time.Second)
+	handleMessage(t, h, "hot")
+
+	assert.Equal(t, []string{"hot", "hot", "hot", "hot", "other", "hot", "hot"}, rec.Messages())
+	assert.Equal(t, []slog.Attr{slog.Uint64("suppressed", 6)}, rec.attrs)
+}
+
+func TestSamplingHandlerSeparatesLevels(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newSamplingHandler(rec, SamplingOptions{First: 1})
+
+	for _, level := range []slog.Level{slog.LevelInfo, slog.LevelInfo, slog.LevelError, slog.LevelError} {
+		err := h.Handle(context.Background(), slog.NewRecord(time.Now(), level, "msg", 0))
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, len(rec.Messages()))
+}
+
+func TestSamplingHandlerNeverSamplesFatalAndPrint(t *testing.T) {
+	rec := &recordingHandler{}
+	h := newSamplingHandler(rec, SamplingOptions{})
+
+	for _, level := range []slog.Level{LevelFatal, LevelFatal, LevelPrint, LevelPrint} {
+		err := h.Handle(context.Background(), slog.NewRecord(time.Now(), level, "msg", 0))
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 4, len(rec.Messages()))
+	assert.Equal(t, uint64(0), h.suppressed())
+}
+
+func TestSamplingConfig(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logLevel.Set(slog.LevelDebug)
+	l := New(&Config{Sampling: &SamplingOptions{Interval: time.Hour, First: 1}, out: buf})
+
+	for range 5 {
+		l.Error("retry failed")
+		l.Print("print\n")
+	}
+	assert.NoError(t, l.Flush())
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "retry failed"))
+	assert.Contains(t, buf.String(), "msg=\"retry failed\" suppressed=4")
+	assert.Equal(t, 5, strings.Count(buf.String(), "print\n"))
+	assert.Equal(t, uint64(4), l.Suppressed())
+	assert.Equal(t, uint64(0), New(&Config{out: buf}).Suppressed())
+}
@@ -0,0 +1,43 @@
+package eslog
+
+import (
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// packagePrefix is the prefix of all function names of this package. It is used to skip the
+// frames of eslog wrappers when looking up the caller.
+const packagePrefix = "github.com/steffakasid/eslog."
+
+// maxCallerDepth limits the frames inspected by callerPC.
+const maxCallerDepth = 32
+
+// callerPC returns the program counter of the first caller outside of eslog. That way
+// records point to the call site in the program, no matter through how many eslog wrappers
+// (package functions, methods, LogIfError, ...) the record was logged.
+func callerPC() uintptr {
+	var pcs [maxCallerDepth]uintptr
+	// Skip runtime.Callers and callerPC.
+	n := runtime.Callers(2, pcs[:])
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) {
+			return pc
+		}
+	}
+	return 0
+}
+
+// trimSource returns a copy of src with File made relative to root. If File is not below
+// root src is returned unchanged.
+func trimSource(src *slog.Source, root string) *slog.Source {
+	rel, err := filepath.Rel(root, src.File)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return src
+	}
+	trimmed := *src
+	trimmed.File = filepath.ToSlash(rel)
+	return &trimmed
+}
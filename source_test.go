@@ -0,0 +1,110 @@
+package eslog_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/steffakasid/eslog"
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// line returns the line of its caller.
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l
+}
+
+// failingCloser fails to close.
+type failingCloser struct{}
+
+func (failingCloser) Close() error {
+	return os.ErrClosed
+}
+
+func TestAddSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		logFunc func() int
+	}{
+		{"Debug", func() int { eslog.Debug("msg"); return line() }},
+		{"Debugf", func() int { eslog.Debugf("msg %d", 1); return line() }},
+		{"DebugLn", func() int { eslog.DebugLn("msg"); return line() }},
+		{"Info", func() int { eslog.Info("msg"); return line() }},
+		{"Infof", func() int { eslog.Infof("msg %d", 1); return line() }},
+		{"InfoLn", func() int { eslog.InfoLn("msg"); return line() }},
+		{"Warn", func() int { eslog.Warn("msg"); return line() }},
+		{"Warnf", func() int { eslog.Warnf("msg %d", 1); return line() }},
+		{"Error", func() int { eslog.Error("msg"); return line() }},
+		{"Errorf", func() int { eslog.Errorf("msg %d", 1); return line() }},
+		{"ErrorLn", func() int { eslog.ErrorLn("msg"); return line() }},
+		{"method Infof", func() int { eslog.Logger.Infof("msg %d", 1); return line() }},
+		{"method WarnLn", func() int { eslog.Logger.WarnLn("msg"); return line() }},
+		{"slog method", func() int { eslog.Logger.Info("msg"); return line() }},
+		{"LogIfError", func() int { eslog.LogIfError(os.ErrClosed, eslog.Error); return line() }},
+		{"LogIfErrorf", func() int { eslog.LogIfErrorf(os.ErrClosed, eslog.Errorf, "msg %s"); return line() }},
+		{"CheckErr", func() int { eslog.CheckErr(os.ErrClosed, "msg"); return line() }},
+		{"LogClose", func() int { eslog.LogClose(failingCloser{}, "msg"); return line() }},
+	}
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			assert.NoError(t, err)
+
+			eslog.New(&eslog.Config{AddSource: true, SourceRoot: wd}).SetOutput(w)
+			defer eslog.New(&eslog.Config{}).SetOutput(os.Stdout)
+			err = eslog.Logger.SetLogLevel("Debug")
+			assert.NoError(t, err)
+
+			expectedLine := tt.logFunc()
+			err = w.Close()
+			assert.NoError(t, err)
+
+			out, err := io.ReadAll(r)
+			assert.NoError(t, err)
+
+			assert.Contains(t, string(out), fmt.Sprintf("source=source_test.go:%d ", expectedLine))
+		})
+	}
+}
+
+func TestAddSourceWithoutSourceRoot(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+
+	eslog.New(&eslog.Config{AddSource: true}).SetOutput(w)
+	defer eslog.New(&eslog.Config{}).SetOutput(os.Stdout)
+	err = eslog.Logger.SetLogLevel("Debug")
+	assert.NoError(t, err)
+
+	eslog.Info("msg")
+	_, file, _, _ := runtime.Caller(0)
+	err = w.Close()
+	assert.NoError(t, err)
+
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(out), "source="+file+":")
+}
+
+func TestNoSourceByDefault(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+
+	eslog.Logger.SetOutput(w)
+	eslog.Error("msg")
+	err = w.Close()
+	assert.NoError(t, err)
+
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, string(out), "source=")
+}
@@ -0,0 +1,402 @@
+package eslog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of SpoolOptions.
+const (
+	defaultSpoolSegmentSize   = 4 << 20
+	defaultSpoolMaxSize       = 64 << 20
+	defaultSpoolRetryInterval = 5 * time.Second
+)
+
+const (
+	// spoolHeaderSize is the size of the length and the CRC-32 checksum in front of each
+	// entry of a segment.
+	spoolHeaderSize = 8
+	spoolExt        = ".spool"
+	// spoolCursorFile stores the segment and the offset of the oldest entry not yet
+	// replayed.
+	spoolCursorFile = "cursor"
+)
+
+var errSpoolClosed = errors.New("eslog: spool closed")
+
+// SpoolOptions configures a Spool.
+type SpoolOptions struct {
+	// SegmentSize is the size at which a new segment file is started. Defaults to 4 MiB.
+	SegmentSize int64
+	// MaxSize is the maximum size of all segments. If it is exceeded the oldest segments
+	// are evicted. Defaults to 64 MiB.
+	MaxSize int64
+	// RetryInterval is the interval in which the destination is retried while the spool
+	// is not empty. Defaults to 5 seconds.
+	RetryInterval time.Duration
+	// Sync calls fsync after each entry. It makes the spool survive power loss, but is
+	// slow.
+	Sync bool
+}
+
+// Spool is a durable on-disk queue used to store records while the destination of a
+// network handler is down. It consists of append-only segment files whose entries are
+// protected by checksums. Entries are replayed in order once the destination recovers.
+//
+// Use it with BatchOptions.Spool for OTLPHandler and LokiHandler or with NewSpoolHandler
+// for other handlers. The handler closes the spool.
+type Spool struct {
+	dir  string
+	opts SpoolOptions
+
+	mu sync.Mutex
+	// segments are the sequence numbers of the segment files in ascending order. The last
+	// one is written to, entries are read from the first one.
+	segments []uint64
+	w        *os.File
+	wSize    int64
+	r        *os.File
+	rSeq     uint64
+	readOff  int64
+	// next is the size of the entry returned by the last peek.
+	next    int64
+	size    int64
+	evicted uint64
+	closed  bool
+}
+
+// OpenSpool opens the spool in dir, creating dir if it doesn't exist. Entries spooled by a
+// previous run are kept. An incomplete entry at the end, e.g. after a crash, is removed.
+func OpenSpool(dir string, opts SpoolOptions) (*Spool, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = defaultSpoolSegmentSize
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultSpoolMaxSize
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultSpoolRetryInterval
+	}
+	opts.SegmentSize = min(opts.SegmentSize, opts.MaxSize)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	s := &Spool{dir: dir, opts: opts}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Size returns the size of all segment files.
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Evicted returns the number of entries evicted because the spool exceeded MaxSize.
+func (s *Spool) Evicted() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evicted
+}
+
+// Close closes the segment files. The entries are kept for the next run.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.closeReader()
+	return s.w.Close()
+}
+
+// load reads the segments and the cursor of dir and opens the last segment for writing.
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if seq, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), spoolExt), 10, 64); err == nil && strings.HasSuffix(e.Name(), spoolExt) {
+			s.segments = append(s.segments, seq)
+		}
+	}
+	slices.Sort(s.segments)
+
+	// Segments before the cursor are already replayed.
+	if data, err := os.ReadFile(filepath.Join(s.dir, spoolCursorFile)); err == nil {
+		var seq uint64
+		var off int64
+		if _, err := fmt.Sscanf(string(data), "%d %d", &seq, &off); err == nil {
+			for len(s.segments) > 1 && s.segments[0] < seq {
+				s.removeOldest()
+			}
+			if len(s.segments) > 0 && s.segments[0] == seq {
+				s.readOff = off
+			}
+		}
+	}
+	if len(s.segments) == 0 {
+		s.segments = []uint64{1}
+	}
+
+	for _, seq := range s.segments {
+		if info, err := os.Stat(s.segmentPath(seq)); err == nil {
+			s.size += info.Size()
+		}
+	}
+	return s.openWriter()
+}
+
+// openWriter opens the last segment for writing and truncates it after its last complete
+// entry.
+func (s *Spool) openWriter() error {
+	path := s.segmentPath(s.segments[len(s.segments)-1])
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	var off int64
+	for {
+		payload, err := readSpoolEntry(f, off, info.Size())
+		if err != nil {
+			break
+		}
+		off += spoolHeaderSize + int64(len(payload))
+	}
+	if info.Size() != off {
+		s.size -= info.Size() - off
+		err = f.Truncate(off)
+	}
+	if err == nil {
+		_, err = f.Seek(off, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.w, s.wSize = f, off
+	return nil
+}
+
+func (s *Spool) segmentPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolExt))
+}
+
+// append adds payload as newest entry. The oldest segments are evicted if the spool gets
+// larger than MaxSize.
+func (s *Spool) append(payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errSpoolClosed
+	}
+	entry := make([]byte, spoolHeaderSize, spoolHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(entry, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(entry[4:], crc32.ChecksumIEEE(payload))
+	entry = append(entry, payload...)
+
+	if s.wSize > 0 && s.wSize+int64(len(entry)) > s.opts.SegmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.w.Write(entry); err != nil {
+		// Remove the incomplete entry, so the segment stays readable.
+		_ = s.w.Truncate(s.wSize)
+		_, _ = s.w.Seek(s.wSize, io.SeekStart)
+		return err
+	}
+	s.wSize += int64(len(entry))
+	s.size += int64(len(entry))
+	var err error
+	if s.opts.Sync {
+		err = s.w.Sync()
+	}
+
+	for s.size > s.opts.MaxSize && len(s.segments) > 1 {
+		s.evicted += s.countEntries()
+		s.removeOldest()
+		s.readOff = 0
+		s.saveCursor()
+	}
+	return err
+}
+
+// rotate starts a new segment. s.mu must be held.
+func (s *Spool) rotate() error {
+	if err := s.w.Close(); err != nil {
+		return err
+	}
+	seq := s.segments[len(s.segments)-1] + 1
+	f, err := os.OpenFile(s.segmentPath(seq), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	s.segments = append(s.segments, seq)
+	s.w, s.wSize = f, 0
+	return nil
+}
+
+// peek returns the oldest entry or nil if the spool is empty. Segments which are completely
+// replayed are removed. The rest of a segment is skipped if an entry is corrupted.
+func (s *Spool) peek() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !s.closed {
+		last := len(s.segments) == 1
+		if last && s.readOff >= s.wSize {
+			return nil, nil
+		}
+		if err := s.openReader(); err != nil {
+			return nil, err
+		}
+		payload, err := readSpoolEntry(s.r, s.readOff, s.readerSize())
+		if err == nil {
+			s.next = spoolHeaderSize + int64(len(payload))
+			return payload, nil
+		}
+		if last {
+			// Entries of the segment being written are complete, so this is a real
+			// read error.
+			return nil, err
+		}
+		s.removeOldest()
+		s.readOff = 0
+		s.saveCursor()
+	}
+	return nil, errSpoolClosed
+}
+
+// ack removes the entry returned by the last peek.
+func (s *Spool) ack() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readOff += s.next
+	s.next = 0
+	s.saveCursor()
+}
+
+// empty reports whether all entries are replayed.
+func (s *Spool) empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.segments) == 1 && s.readOff >= s.wSize
+}
+
+// openReader opens the oldest segment for reading. s.mu must be held.
+func (s *Spool) openReader() error {
+	if s.r != nil && s.rSeq == s.segments[0] {
+		return nil
+	}
+	s.closeReader()
+	f, err := os.Open(s.segmentPath(s.segments[0]))
+	if err != nil {
+		return err
+	}
+	s.r, s.rSeq = f, s.segments[0]
+	return nil
+}
+
+// readerSize returns the size of the oldest segment. s.mu must be held.
+func (s *Spool) readerSize() int64 {
+	if len(s.segments) == 1 {
+		return s.wSize
+	}
+	info, err := s.r.Stat()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func (s *Spool) closeReader() {
+	if s.r != nil {
+		_ = s.r.Close()
+		s.r = nil
+	}
+}
+
+// removeOldest deletes the oldest segment. s.mu must be held.
+func (s *Spool) removeOldest() {
+	path := s.segmentPath(s.segments[0])
+	if s.rSeq == s.segments[0] {
+		s.closeReader()
+	}
+	if info, err := os.Stat(path); err == nil {
+		s.size -= info.Size()
+	}
+	_ = os.Remove(path)
+	s.segments = s.segments[1:]
+}
+
+// countEntries returns the number of entries of the oldest segment which are not
+// replayed yet. s.mu must be held.
+func (s *Spool) countEntries() uint64 {
+	if err := s.openReader(); err != nil {
+		return 0
+	}
+	var n uint64
+	for off := s.readOff; ; n++ {
+		payload, err := readSpoolEntry(s.r, off, s.readerSize())
+		if err != nil {
+			return n
+		}
+		off += spoolHeaderSize + int64(len(payload))
+	}
+}
+
+// saveCursor stores the position of the oldest entry. s.mu must be held.
+func (s *Spool) saveCursor() {
+	cursor := fmt.Sprintf("%d %d\n", s.segments[0], s.readOff)
+	_ = os.WriteFile(filepath.Join(s.dir, spoolCursorFile), []byte(cursor), 0o600)
+}
+
+// readSpoolEntry reads the entry at off of f and verifies its checksum. size is the size of
+// the segment.
+func readSpoolEntry(f *os.File, off, size int64) ([]byte, error) {
+	var header [spoolHeaderSize]byte
+	if off+spoolHeaderSize > size {
+		return nil, io.EOF
+	}
+	if _, err := f.ReadAt(header[:], off); err != nil {
+		return nil, err
+	}
+	length := int64(binary.LittleEndian.Uint32(header[:]))
+	if off+spoolHeaderSize+length > size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	payload := make([]byte, length)
+	if _, err := f.ReadAt(payload, off+spoolHeaderSize); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:]) {
+		return nil, fmt.Errorf("eslog: corrupted spool entry in %s at offset %d", f.Name(), off)
+	}
+	return payload, nil
+}
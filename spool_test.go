@@ -0,0 +1,102 @@
+package eslog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// drainSpool returns all entries of s and acknowledges them.
+func drainSpool(t *testing.T, s *Spool) []string {
+	t.Helper()
+	var entries []string
+	for {
+		payload, err := s.peek()
+		assert.NoError(t, err)
+		if payload == nil {
+			return entries
+		}
+		entries = append(entries, string(payload))
+		s.ack()
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+spoolExt))
+	assert.NoError(t, err)
+	return files
+}
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSpool(dir, SpoolOptions{SegmentSize: 32})
+	assert.NoError(t, err)
+	assert.Equal(t, true, s.empty())
+
+	for i := range 5 {
+		assert.NoError(t, s.append([]byte(fmt.Sprintf("entry %d", i))))
+	}
+	// Each entry has 15 bytes, so a segment holds two entries.
+	assert.Equal(t, 3, len(segmentFiles(t, dir)))
+	assert.Equal(t, int64(5*15), s.Size())
+
+	payload, err := s.peek()
+	assert.NoError(t, err)
+	assert.Equal(t, "entry 0", string(payload))
+	s.ack()
+	assert.NoError(t, s.Close())
+
+	// The cursor survives a restart.
+	s, err = OpenSpool(dir, SpoolOptions{SegmentSize: 32})
+	assert.NoError(t, err)
+	assert.NoError(t, s.append([]byte("entry 5")))
+	assert.Equal(t, []string{"entry 1", "entry 2", "entry 3", "entry 4", "entry 5"}, drainSpool(t, s))
+	assert.Equal(t, true, s.empty())
+	assert.Equal(t, 1, len(segmentFiles(t, dir)))
+	assert.NoError(t, s.Close())
+}
+
+func TestSpoolEviction(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSpool(dir, SpoolOptions{SegmentSize: 32, MaxSize: 64})
+	assert.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	for i := range 10 {
+		assert.NoError(t, s.append([]byte(fmt.Sprintf("entry %d", i))))
+	}
+
+	// The oldest segments are evicted, so at most 64 bytes are left.
+	assert.Equal(t, true, s.Size() <= 64)
+	assert.Equal(t, uint64(6), s.Evicted())
+	assert.Equal(t, []string{"entry 6", "entry 7", "entry 8", "entry 9"}, drainSpool(t, s))
+}
+
+func TestSpoolCorruption(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSpool(dir, SpoolOptions{SegmentSize: 32})
+	assert.NoError(t, err)
+	for i := range 5 {
+		assert.NoError(t, s.append([]byte(fmt.Sprintf("entry %d", i))))
+	}
+	assert.NoError(t, s.Close())
+
+	files := segmentFiles(t, dir)
+	// Corrupt the payload of "entry 1" in the first segment.
+	data, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	data[15+spoolHeaderSize] = 'E'
+	assert.NoError(t, os.WriteFile(files[0], data, 0o600))
+	// Simulate a crash while "entry 4" was written to the last segment.
+	assert.NoError(t, os.Truncate(files[2], 10))
+
+	s, err = OpenSpool(dir, SpoolOptions{SegmentSize: 32})
+	assert.NoError(t, err)
+	assert.NoError(t, s.append([]byte("entry 5")))
+	assert.Equal(t, []string{"entry 0", "entry 2", "entry 3", "entry 5"}, drainSpool(t, s))
+	assert.NoError(t, s.Close())
+}
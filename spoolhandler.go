@@ -0,0 +1,234 @@
+package eslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SpoolHandler passes records to a network handler like SyslogHandler or GELFHandler. If
+// the handler fails the record is stored in a Spool and replayed in order once the handler
+// succeeds again. While the spool is not empty new records are spooled, too, to keep the
+// order and to not block logging while the destination is down. The destination is
+// retried once per SpoolOptions.RetryInterval.
+//
+// Spooled records keep their time, level, message and attributes. Attribute values which
+// are not strings, numbers, booleans, durations or times are stored as strings.
+type SpoolHandler struct {
+	h     slog.Handler
+	attrs attrState
+	state *spoolState
+}
+
+// spoolState is shared between a SpoolHandler and the handlers derived from it.
+type spoolState struct {
+	spool *Spool
+	h     slog.Handler
+
+	// mu serializes the delivery, so records are passed to h in order.
+	mu     sync.Mutex
+	closed bool
+	// replayMu serializes the replays. Records are only passed to h directly while the
+	// spool is empty, so they don't overtake replayed records.
+	replayMu sync.Mutex
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// spoolRecord is the spooled representation of a record.
+type spoolRecord struct {
+	Time    time.Time   `json:"t"`
+	Level   slog.Level  `json:"l"`
+	Message string      `json:"m"`
+	Attrs   []spoolAttr `json:"a,omitempty"`
+}
+
+type spoolAttr struct {
+	Groups []string  `json:"g,omitempty"`
+	Key    string    `json:"k"`
+	Kind   slog.Kind `json:"t"`
+	Value  string    `json:"v"`
+}
+
+// NewSpoolHandler wraps h in a SpoolHandler using spool and starts its background
+// goroutine. The SpoolHandler closes spool.
+func NewSpoolHandler(h slog.Handler, spool *Spool) *SpoolHandler {
+	state := &spoolState{spool: spool, h: h, stop: make(chan struct{})}
+	state.done.Add(1)
+	go state.retry()
+	return &SpoolHandler{h: h, state: state}
+}
+
+func (s *SpoolHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.h.Enabled(ctx, level)
+}
+
+// Handle passes r to the wrapped handler. It only returns an error if r can't be spooled.
+func (s *SpoolHandler) Handle(ctx context.Context, r slog.Record) error {
+	nested := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	fields := s.attrs.recordFields(r)
+	nested.AddAttrs(nestFields(fields, 0)...)
+	return s.state.deliver(ctx, nested, fields)
+}
+
+// WithAttrs returns a handler which spools the attributes with each record. The wrapped
+// handler receives them as record attributes.
+func (s *SpoolHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SpoolHandler{h: s.h, attrs: s.attrs.withAttrs(attrs), state: s.state}
+}
+
+func (s *SpoolHandler) WithGroup(name string) slog.Handler {
+	return &SpoolHandler{h: s.h, attrs: s.attrs.withGroup(name), state: s.state}
+}
+
+// Flush replays the spooled records if the wrapped handler succeeds and flushes it.
+func (s *SpoolHandler) Flush() error {
+	return errors.Join(s.state.replay(context.Background()), flushHandler(s.h))
+}
+
+// Close stops the background goroutine, tries to replay the spooled records once more and
+// closes the spool and the wrapped handler. Records which are still spooled are replayed
+// by the next run.
+func (s *SpoolHandler) Close() error {
+	st := s.state
+	st.mu.Lock()
+	if st.closed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.closed = true
+	close(st.stop)
+	st.mu.Unlock()
+	st.done.Wait()
+
+	_ = st.replay(context.Background())
+	return errors.Join(st.spool.Close(), closeHandler(s.h))
+}
+
+// deliver passes r to the wrapped handler if the spool is empty. Otherwise, or if the
+// handler fails, r is spooled and replayed later by retry. fields are the flattened
+// attributes of r.
+func (st *spoolState) deliver(ctx context.Context, r slog.Record, fields []field) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.closed {
+		return ErrHandlerClosed
+	}
+	if st.spool.empty() && safeHandle(ctx, st.h, r) == nil {
+		return nil
+	}
+	return st.spool.append(encodeSpoolRecord(r, fields))
+}
+
+// replay passes the spooled records to the wrapped handler until the spool is empty or
+// the handler fails.
+func (st *spoolState) replay(ctx context.Context) error {
+	st.replayMu.Lock()
+	defer st.replayMu.Unlock()
+	for {
+		payload, err := st.spool.peek()
+		if err != nil || payload == nil {
+			return err
+		}
+		// Undecodable records are skipped like corrupted entries.
+		if r, err := decodeSpoolRecord(payload); err == nil {
+			if err := safeHandle(ctx, st.h, r); err != nil {
+				return err
+			}
+		}
+		st.spool.ack()
+	}
+}
+
+// retry replays the spool once per retry interval.
+func (st *spoolState) retry() {
+	defer st.done.Done()
+	ticker := time.NewTicker(st.spool.opts.RetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = st.replay(context.Background())
+		case <-st.stop:
+			return
+		}
+	}
+}
+
+func encodeSpoolRecord(r slog.Record, fields []field) []byte {
+	sr := spoolRecord{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: make([]spoolAttr, 0, len(fields))}
+	for _, f := range fields {
+		a := spoolAttr{Groups: f.groups, Key: f.key, Kind: f.value.Kind()}
+		switch f.value.Kind() {
+		case slog.KindInt64:
+			a.Value = strconv.FormatInt(f.value.Int64(), 10)
+		case slog.KindUint64:
+			a.Value = strconv.FormatUint(f.value.Uint64(), 10)
+		case slog.KindFloat64:
+			a.Value = strconv.FormatFloat(f.value.Float64(), 'g', -1, 64)
+		case slog.KindBool:
+			a.Value = strconv.FormatBool(f.value.Bool())
+		case slog.KindDuration:
+			a.Value = strconv.FormatInt(int64(f.value.Duration()), 10)
+		case slog.KindTime:
+			a.Value = f.value.Time().Format(time.RFC3339Nano)
+		default:
+			a.Kind = slog.KindString
+			a.Value = valueString(f.value)
+		}
+		sr.Attrs = append(sr.Attrs, a)
+	}
+	// spoolRecord only contains types which can be marshalled.
+	payload, _ := json.Marshal(sr)
+	return payload
+}
+
+func decodeSpoolRecord(payload []byte) (slog.Record, error) {
+	var sr spoolRecord
+	if err := json.Unmarshal(payload, &sr); err != nil {
+		return slog.Record{}, err
+	}
+
+	fields := make([]field, 0, len(sr.Attrs))
+	for _, a := range sr.Attrs {
+		v, err := decodeSpoolValue(a.Kind, a.Value)
+		if err != nil {
+			return slog.Record{}, err
+		}
+		fields = append(fields, field{groups: a.Groups, key: a.Key, value: v})
+	}
+	r := slog.NewRecord(sr.Time, sr.Level, sr.Message, 0)
+	r.AddAttrs(nestFields(fields, 0)...)
+	return r, nil
+}
+
+func decodeSpoolValue(kind slog.Kind, s string) (slog.Value, error) {
+	switch kind {
+	case slog.KindInt64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		return slog.Int64Value(i), err
+	case slog.KindUint64:
+		u, err := strconv.ParseUint(s, 10, 64)
+		return slog.Uint64Value(u), err
+	case slog.KindFloat64:
+		f, err := strconv.ParseFloat(s, 64)
+		return slog.Float64Value(f), err
+	case slog.KindBool:
+		b, err := strconv.ParseBool(s)
+		return slog.BoolValue(b), err
+	case slog.KindDuration:
+		d, err := strconv.ParseInt(s, 10, 64)
+		return slog.DurationValue(time.Duration(d)), err
+	case slog.KindTime:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return slog.TimeValue(t), err
+	default:
+		return slog.StringValue(s), nil
+	}
+}
@@ -0,0 +1,99 @@
+package eslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// flakyHandler is a recordingHandler which fails while down is set.
+type flakyHandler struct {
+	recordingHandler
+	down     atomic.Bool
+	attempts atomic.Int32
+}
+
+func (h *flakyHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.attempts.Add(1)
+	if h.down.Load() {
+		return errors.New("destination unreachable")
+	}
+	return h.recordingHandler.Handle(ctx, r)
+}
+
+func TestSpoolHandler(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, SpoolOptions{RetryInterval: time.Hour})
+	assert.NoError(t, err)
+	dest := &flakyHandler{}
+	dest.down.Store(true)
+	h := NewSpoolHandler(dest, spool)
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	r := slog.NewRecord(ts, slog.LevelWarn, "first", 0)
+	r.Add("count", 3, "ratio", 0.5, "ok", true, "took", time.Second, "at", ts, "err", errors.New("boom"))
+	handler := h.WithAttrs([]slog.Attr{slog.String("service", "api")}).WithGroup("req")
+	assert.NoError(t, handler.Handle(context.Background(), r))
+	handleMessage(t, h, "second")
+	assert.Equal(t, 0, len(dest.Messages()))
+	// While records are spooled new records are spooled without trying the destination.
+	assert.Equal(t, int32(1), dest.attempts.Load())
+
+	// The destination recovers, new records are spooled behind the ones not yet replayed.
+	dest.down.Store(false)
+	handleMessage(t, h, "third")
+	assert.Equal(t, 0, len(dest.Messages()))
+	assert.NoError(t, h.Flush())
+	assert.Equal(t, []string{"first", "second", "third"}, dest.Messages())
+
+	// With an empty spool records are passed to the destination directly.
+	handleMessage(t, h, "fourth")
+	assert.Equal(t, []string{"first", "second", "third", "fourth"}, dest.Messages())
+
+	expected := []slog.Attr{
+		slog.String("service", "api"),
+		slog.Group("req",
+			slog.Int64("count", 3),
+			slog.Float64("ratio", 0.5),
+			slog.Bool("ok", true),
+			slog.Duration("took", time.Second),
+			slog.Time("at", ts),
+			slog.String("err", "boom"),
+		),
+	}
+	assert.Equal(t, expected, dest.attrs)
+	assert.NoError(t, h.Close())
+	assert.Equal(t, ErrHandlerClosed, h.Handle(context.Background(), r))
+}
+
+func TestSpoolHandlerReplaysAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, SpoolOptions{})
+	assert.NoError(t, err)
+	dest := &flakyHandler{}
+	dest.down.Store(true)
+	h := NewSpoolHandler(dest, spool)
+	handleMessage(t, h, "spooled")
+	assert.NoError(t, h.Close())
+
+	spool, err = OpenSpool(dir, SpoolOptions{RetryInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	dest.down.Store(false)
+	h = NewSpoolHandler(dest, spool)
+	defer func() { _ = h.Close() }()
+
+	// The spool is replayed in the background without new records.
+	deadline := time.Now().Add(5 * time.Second)
+	for len(dest.Messages()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("spooled record was not replayed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"spooled"}, dest.Messages())
+}
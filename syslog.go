@@ -0,0 +1,271 @@
+package eslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogFormat selects the syslog message format.
+type SyslogFormat int
+
+const (
+	// RFC5424 is the current syslog protocol. Attributes are sent as structured data.
+	RFC5424 SyslogFormat = iota
+	// RFC3164 is the legacy BSD syslog format. Attributes are appended to the message as
+	// key=value pairs.
+	RFC3164
+)
+
+// Syslog severities as defined by RFC 5424. Emergency (0) is not used by eslog.
+const (
+	severityAlert    = 1
+	severityCritical = 2
+	severityError    = 3
+	severityWarning  = 4
+	severityNotice   = 5
+	severityInfo     = 6
+	severityDebug    = 7
+)
+
+// FacilityUser is the syslog facility for user-level messages. It is used by default.
+const FacilityUser = 1
+
+// defaultStructuredDataID is the SD-ID of the attributes in RFC5424 messages. 32473 is the
+// private enterprise number reserved for documentation.
+const defaultStructuredDataID = "eslog@32473"
+
+// syslogLocalAddrs are the sockets of the local syslog daemon.
+var syslogLocalAddrs = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogOptions configures a SyslogHandler.
+type SyslogOptions struct {
+	// Network is one of "unixgram", "unix", "udp" or "tcp" (or their variants like "tcp4").
+	// If Network and Addr are empty the local syslog daemon is used.
+	Network string
+	// Addr is the address of the syslog server or the path of the unix socket.
+	Addr string
+	// Format is the message format. Defaults to RFC5424.
+	Format SyslogFormat
+	// Facility is the syslog facility. Defaults to FacilityUser.
+	Facility int
+	// AppName identifies the program. Defaults to the name of the executable.
+	AppName string
+	// Hostname defaults to os.Hostname().
+	Hostname string
+	// StructuredDataID is the SD-ID used for the attributes in RFC5424 messages. Defaults
+	// to "eslog@32473".
+	StructuredDataID string
+	// Level is the minimum level of the handler. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+	// Timeout is used to connect and write. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// SyslogHandler sends records to a syslog server. Messages are framed with octet-counting
+// on TCP. If sending fails the connection is reestablished and the message is sent again.
+type SyslogHandler struct {
+	opts  *SyslogOptions
+	pid   int
+	attrs attrState
+	conn  *reconnectingConn
+}
+
+// NewSyslogHandler creates a SyslogHandler and connects to the syslog server.
+func NewSyslogHandler(opts SyslogOptions) (*SyslogHandler, error) {
+	if opts.Facility == 0 {
+		opts.Facility = FacilityUser
+	}
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.StructuredDataID == "" {
+		opts.StructuredDataID = defaultStructuredDataID
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelInfo
+	}
+
+	h := &SyslogHandler{opts: &opts, pid: os.Getpid()}
+	if opts.Network == "" && opts.Addr == "" {
+		return h, h.connectLocal()
+	}
+	h.conn = newReconnectingConn(opts.Network, opts.Addr, opts.Timeout)
+	return h, h.conn.connect()
+}
+
+// connectLocal connects to the first available local syslog socket.
+func (h *SyslogHandler) connectLocal() error {
+	var errs []error
+	for _, addr := range syslogLocalAddrs {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn := newReconnectingConn(network, addr, h.opts.Timeout)
+			err := conn.connect()
+			if err == nil {
+				h.opts.Network, h.opts.Addr, h.conn = network, addr, conn
+				return nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return fmt.Errorf("eslog: no local syslog daemon found: %w", errors.Join(errs...))
+}
+
+func (h *SyslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+func (h *SyslogHandler) Handle(_ context.Context, r slog.Record) error {
+	var msg []byte
+	if h.opts.Format == RFC3164 {
+		msg = h.appendRFC3164(nil, r)
+	} else {
+		msg = h.appendRFC5424(nil, r)
+	}
+	return h.conn.write(h.frame(msg))
+}
+
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SyslogHandler{opts: h.opts, pid: h.pid, attrs: h.attrs.withAttrs(attrs), conn: h.conn}
+}
+
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	return &SyslogHandler{opts: h.opts, pid: h.pid, attrs: h.attrs.withGroup(name), conn: h.conn}
+}
+
+// Close closes the connection to the syslog server.
+func (h *SyslogHandler) Close() error {
+	return h.conn.close()
+}
+
+// frame frames msg for the transport: octet-counting on TCP (RFC 6587), a trailing newline
+// on unix stream sockets and nothing for datagrams.
+func (h *SyslogHandler) frame(msg []byte) []byte {
+	switch h.opts.Network {
+	case "tcp", "tcp4", "tcp6":
+		return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	case "unix":
+		return append(msg, '\n')
+	default:
+		return msg
+	}
+}
+
+func (h *SyslogHandler) priority(level slog.Level) int {
+	return h.opts.Facility*8 + syslogSeverity(level)
+}
+
+// appendRFC5424 appends r formatted as RFC 5424 message to b:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID PARAM="VALUE"...] MSG
+func (h *SyslogHandler) appendRFC5424(b []byte, r slog.Record) []byte {
+	b = fmt.Appendf(b, "<%d>1 %s %s %s %d - ",
+		h.priority(r.Level),
+		r.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(h.opts.Hostname, 255),
+		syslogHeaderField(h.opts.AppName, 48),
+		h.pid)
+
+	fields := h.attrs.recordFields(r)
+	if len(fields) == 0 {
+		b = append(b, '-')
+	} else {
+		b = append(b, '[')
+		b = append(b, h.opts.StructuredDataID...)
+		for _, f := range fields {
+			b = append(b, ' ')
+			b = append(b, sdParamName(f.name("."))...)
+			b = append(b, '=', '"')
+			b = append(b, sdParamValueEscaper.Replace(valueString(f.value))...)
+			b = append(b, '"')
+		}
+		b = append(b, ']')
+	}
+	if r.Message != "" {
+		b = append(b, ' ')
+		b = append(b, r.Message...)
+	}
+	return b
+}
+
+// appendRFC3164 appends r formatted as RFC 3164 message to b:
+// <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG key=value...
+func (h *SyslogHandler) appendRFC3164(b []byte, r slog.Record) []byte {
+	b = fmt.Appendf(b, "<%d>%s %s %s[%d]: %s",
+		h.priority(r.Level),
+		r.Time.Format(time.Stamp),
+		syslogHeaderField(h.opts.Hostname, 255),
+		syslogHeaderField(h.opts.AppName, 32),
+		h.pid,
+		r.Message)
+	if fields := h.attrs.recordFields(r); len(fields) > 0 {
+		b = append(b, ' ')
+		b = appendLogfmt(b, fields)
+	}
+	return b
+}
+
+// syslogSeverity maps level to a syslog severity. Custom levels are mapped to the
+// severity of the next lower known level, levels above LevelFatal to alert.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level > LevelFatal:
+		return severityAlert
+	case level == LevelFatal:
+		return severityCritical
+	case level >= slog.LevelError:
+		return severityError
+	case level >= slog.LevelWarn:
+		return severityWarning
+	case level > slog.LevelInfo:
+		return severityNotice
+	case level == slog.LevelInfo:
+		return severityInfo
+	default:
+		return severityDebug
+	}
+}
+
+// syslogHeaderField returns s usable as header field: printable ASCII without spaces, at
+// most maxLen characters, "-" if empty.
+func syslogHeaderField(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sdParamName returns name usable as SD-PARAM name: printable ASCII except '=', ' ', ']'
+// and '"', at most 32 characters.
+func sdParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// sdParamValueEscaper escapes the characters which must be escaped in SD-PARAM values.
+var sdParamValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
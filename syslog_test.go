@@ -0,0 +1,193 @@
+package eslog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+// syslogTime is the time of the records in the syslog tests.
+var syslogTime = time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+func syslogRecord(level slog.Level, msg string, args ...any) slog.Record {
+	r := slog.NewRecord(syslogTime, level, msg, 0)
+	r.Add(args...)
+	return r
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected int
+	}{
+		{LevelTrace, severityDebug},
+		{slog.LevelDebug, severityDebug},
+		{slog.LevelInfo, severityInfo},
+		{slog.LevelInfo + 2, severityNotice},
+		{slog.LevelWarn, severityWarning},
+		{slog.LevelError, severityError},
+		{slog.LevelError + 2, severityError},
+		{LevelFatal, severityCritical},
+		{LevelFatal + 1, severityAlert},
+	}
+
+	for _, tt := range tests {
+		t.Run(levelName(tt.level), func(t *testing.T) {
+			assert.Equal(t, tt.expected, syslogSeverity(tt.level))
+		})
+	}
+}
+
+func TestSyslogUDPRFC5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() { _ = pc.Close() }()
+
+	h, err := NewSyslogHandler(SyslogOptions{Network: "udp", Addr: pc.LocalAddr().String(), AppName: "app", Hostname: "host"})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	handler := h.WithAttrs([]slog.Attr{slog.String("service", "api")}).WithGroup("req")
+	err = handler.Handle(context.Background(), syslogRecord(LevelFatal, "failed", "path", `/a "b" ]c\`, "status", 500))
+	assert.NoError(t, err)
+	err = h.Handle(context.Background(), syslogRecord(slog.LevelInfo, "no attrs"))
+	assert.NoError(t, err)
+
+	expected := []string{
+		fmt.Sprintf(`<10>1 2026-01-02T03:04:05.123456Z host app %d - [eslog@32473 service="api" req.path="/a \"b\" \]c\\" req.status="500"] failed`, os.Getpid()),
+		fmt.Sprintf(`<14>1 2026-01-02T03:04:05.123456Z host app %d - - no attrs`, os.Getpid()),
+	}
+	buf := make([]byte, 2048)
+	for _, e := range expected {
+		assert.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := pc.ReadFrom(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, e, string(buf[:n]))
+	}
+}
+
+func TestSyslogUnixgramRFC3164(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "log.sock")
+	pc, err := net.ListenPacket("unixgram", addr)
+	assert.NoError(t, err)
+	defer func() { _ = pc.Close() }()
+
+	h, err := NewSyslogHandler(SyslogOptions{Network: "unixgram", Addr: addr, Format: RFC3164, Facility: 16, AppName: "app", Hostname: "host"})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	err = h.Handle(context.Background(), syslogRecord(slog.LevelWarn, "disk full", "mount", "/var", "usage", "99 %"))
+	assert.NoError(t, err)
+
+	buf := make([]byte, 2048)
+	assert.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`<132>Jan  2 03:04:05 host app[%d]: disk full mount=/var usage="99 %%"`, os.Getpid()), string(buf[:n]))
+}
+
+// readOctetCounted reads one octet-counted frame from r.
+func readOctetCounted(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	length, err := r.ReadString(' ')
+	assert.NoError(t, err)
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	assert.NoError(t, err)
+	msg := make([]byte, n)
+	_, err = io.ReadFull(r, msg)
+	assert.NoError(t, err)
+	return string(msg)
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+
+	h, err := NewSyslogHandler(SyslogOptions{Network: "tcp", Addr: ln.Addr().String(), AppName: "app", Hostname: "host"})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	conn, err := ln.Accept()
+	assert.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	for _, msg := range []string{"first", "second\nline"} {
+		assert.NoError(t, h.Handle(context.Background(), syslogRecord(slog.LevelError, msg)))
+	}
+
+	r := bufio.NewReader(conn)
+	assert.Contains(t, readOctetCounted(t, r), " - - first")
+	assert.Contains(t, readOctetCounted(t, r), " - - second\nline")
+}
+
+func TestSyslogReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+
+	h, err := NewSyslogHandler(SyslogOptions{Network: "tcp", Addr: ln.Addr().String(), Timeout: time.Second})
+	assert.NoError(t, err)
+	defer func() { _ = h.Close() }()
+
+	// The server drops the first connection.
+	conn, err := ln.Accept()
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Close())
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	// Writes to the dropped connection may succeed until the reset is noticed, so keep
+	// logging until the handler reconnected.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_ = h.Handle(context.Background(), syslogRecord(slog.LevelError, "after reconnect"))
+		select {
+		case conn := <-accepted:
+			defer func() { _ = conn.Close() }()
+			assert.Contains(t, readOctetCounted(t, bufio.NewReader(conn)), "after reconnect")
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("handler did not reconnect")
+		}
+	}
+}
+
+func TestSyslogAsSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() { _ = pc.Close() }()
+
+	h, err := NewSyslogHandler(SyslogOptions{Network: "udp", Addr: pc.LocalAddr().String(), Level: slog.LevelDebug})
+	assert.NoError(t, err)
+	l := New(&Config{Sinks: []Sink{{Handler: h, Level: slog.LevelWarn}}})
+	defer func() { _ = l.Close() }()
+
+	l.Info("filtered by the sink level")
+	l.Error("sent to syslog")
+
+	buf := make([]byte, 2048)
+	assert.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), "sent to syslog")
+}
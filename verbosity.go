@@ -0,0 +1,50 @@
+package eslog
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// LevelTrace is used for very detailed output below [slog.LevelDebug]. It is enabled by
+// the highest verbosity (e.g. -vvv).
+const LevelTrace = slog.Level(-8)
+
+// quiet holds whether the quiet mode is enabled. In quiet mode Print output is suppressed.
+var quiet atomic.Bool
+
+// VerbosityLevel maps a verbosity as given by repeated -v flags of a CLI to a log level.
+// 0 maps to [slog.LevelWarn], 1 (-v) to [slog.LevelInfo], 2 (-vv) to [slog.LevelDebug]
+// and 3 or more (-vvv) to [LevelTrace]. A negative verbosity is used for quiet mode and
+// maps to [slog.LevelError].
+func VerbosityLevel(verbosity int) slog.Level {
+	switch {
+	case verbosity < 0:
+		return slog.LevelError
+	case verbosity == 0:
+		return slog.LevelWarn
+	case verbosity == 1:
+		return slog.LevelInfo
+	case verbosity == 2:
+		return slog.LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// SetVerbosity sets the log level of the Logger according to [VerbosityLevel]. A negative
+// verbosity enables quiet mode, any other verbosity disables it.
+func (l *eSlogLogger) SetVerbosity(verbosity int) {
+	logLevel.Set(VerbosityLevel(verbosity))
+	quiet.Store(verbosity < 0)
+}
+
+// SetQuiet enables or disables quiet mode. In quiet mode Print, Printf, Println and the
+// PrintV family don't write anything. Leveled logging is not affected.
+func (l *eSlogLogger) SetQuiet(q bool) {
+	quiet.Store(q)
+}
+
+// Quiet reports whether quiet mode is enabled.
+func (l *eSlogLogger) Quiet() bool {
+	return quiet.Load()
+}
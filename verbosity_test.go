@@ -0,0 +1,105 @@
+package eslog_test
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/steffakasid/eslog"
+	"github.com/steffakasid/eslog/internal/assert"
+)
+
+func TestVerbosityLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		expected  slog.Level
+	}{
+		{"quiet", -1, slog.LevelError},
+		{"default", 0, slog.LevelWarn},
+		{"-v", 1, slog.LevelInfo},
+		{"-vv", 2, slog.LevelDebug},
+		{"-vvv", 3, eslog.LevelTrace},
+		{"-vvvv", 4, eslog.LevelTrace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, eslog.VerbosityLevel(tt.verbosity))
+		})
+	}
+}
+
+func TestSetVerbosity(t *testing.T) {
+	tests := []struct {
+		name        string
+		verbosity   int
+		expected    []string
+		notExpected []string
+	}{
+		{"quiet", -1, []string{"error message"}, []string{"print message", "warn message", "v1 message"}},
+		{"default", 0, []string{"print message", "warn message", "error message"}, []string{"info message", "v1 message"}},
+		{"-v", 1, []string{"info message", "v1 message"}, []string{"debug message", "v2 message"}},
+		{"-vv", 2, []string{"debug message", "v2 message"}, []string{"trace message", "v3 message"}},
+		{"-vvv", 3, []string{"TRACE", "trace message", "v3 message"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			assert.NoError(t, err)
+
+			eslog.Logger.SetOutput(w)
+			eslog.Logger.SetVerbosity(tt.verbosity)
+			defer eslog.Logger.SetVerbosity(2)
+
+			eslog.Print("print message\n")
+			eslog.PrintV(1, "v1 message\n")
+			eslog.PrintVf(2, "v%d message\n", 2)
+			eslog.PrintVln(3, "v3 message")
+			eslog.Logger.Log(t.Context(), eslog.LevelTrace, "trace message")
+			eslog.Debug("debug message")
+			eslog.Info("info message")
+			eslog.Warn("warn message")
+			eslog.Error("error message")
+			err = w.Close()
+			assert.NoError(t, err)
+
+			out, err := io.ReadAll(r)
+			assert.NoError(t, err)
+
+			for _, e := range tt.expected {
+				assert.Contains(t, string(out), e)
+			}
+			for _, ne := range tt.notExpected {
+				assert.NotContains(t, string(out), ne)
+			}
+		})
+	}
+}
+
+func TestSetQuiet(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+
+	eslog.Logger.SetOutput(w)
+	err = eslog.Logger.SetLogLevel("Debug")
+	assert.NoError(t, err)
+
+	eslog.Logger.SetQuiet(true)
+	assert.Equal(t, true, eslog.Logger.Quiet())
+	eslog.Println("suppressed print")
+	eslog.Info("leveled message")
+	eslog.Logger.SetQuiet(false)
+	eslog.Println("visible print")
+	err = w.Close()
+	assert.NoError(t, err)
+
+	out, err := io.ReadAll(r)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, string(out), "suppressed print")
+	assert.Contains(t, string(out), "leveled message")
+	assert.Contains(t, string(out), "visible print")
+}
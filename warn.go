@@ -1,33 +1,32 @@
 package eslog
 
-import (
-	"fmt"
-	"strings"
-)
+import "log/slog"
 
 // Warn logs at [LevelWarn]. Multiple args are joined with "  ".
 func Warn(args ...any) {
-	Logger.Warn(strings.Join(convertAnyToString(args...), " "))
+	Logger.logArgs(slog.LevelWarn, args...)
 }
 
-// Fatalf logs at [LevelWarn]. The function uses fmt.Sprintf with given format and args
+// Warnf logs at [LevelWarn]. The function uses fmt.Sprintf with given format and args
 // and log it.
 func Warnf(format string, args ...any) {
 	Logger.Warnf(format, args...)
 }
 
-// Fatalf logs at [LevelWarn]. The function uses fmt.Sprintf with given format and args
+// Warnf logs at [LevelWarn]. The function uses fmt.Sprintf with given format and args
 // and log it.
 func (l eSlogLogger) Warnf(format string, args ...any) {
-	l.Warn(fmt.Sprintf(format, args...))
+	l.logf(slog.LevelWarn, format, args...)
 }
 
-// WarnLn logs at [LevelWarn] and appends a newline.
-func WarnLn(msg string, args ...any) {
-	Logger.WarnLn(msg, args...)
+// WarnLn logs at [LevelWarn]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func WarnLn(args ...any) {
+	Logger.WarnLn(args...)
 }
 
-func (l eSlogLogger) WarnLn(msg string, args ...any) {
-	args = append(args, "\n")
-	Logger.Warn(msg, args...)
+// WarnLn logs at [LevelWarn]. The args are formatted like fmt.Sprintln, i.e. always separated
+// by spaces, without the trailing newline.
+func (l eSlogLogger) WarnLn(args ...any) {
+	l.logln(slog.LevelWarn, args...)
 }